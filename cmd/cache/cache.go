@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"fmt"
+	"github.com/satisfactorymodding/SMEI/config"
+	"github.com/satisfactorymodding/SMEI/lib/cache"
+	"github.com/satisfactorymodding/SMEI/lib/cmdhelp"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	for _, cmd := range []*cobra.Command{listCmd, sizeCmd, pruneCmd, clearCmd} {
+		cmd.Flags().StringSliceP("component", "c", nil, "Only these caches: "+strings.Join(componentNames(), ", ")+". Defaults to all of them")
+		Cmd.AddCommand(cmd)
+	}
+}
+
+var Cmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the files SMEI downloaded",
+	Long:  "Manage the files SMEI downloaded. Caches are stored in '" + config.CacheDir + "'",
+	Run: func(cmd *cobra.Command, args []string) {
+		cmdhelp.PrintHelp(cmd)
+	},
+}
+
+func setup() {
+	err := config.Setup()
+	if err != nil {
+		log.Fatalf("Could not set up the config: %v", err)
+	}
+}
+
+func componentNames() []string {
+	var r []string
+	for _, component := range cache.Components {
+		r = append(r, component.Name)
+	}
+	return r
+}
+
+// selected returns the components chosen with --component
+func selected(cmd *cobra.Command) []cache.Component {
+	names, err := cmd.Flags().GetStringSlice("component")
+	if err != nil {
+		log.Fatalf("Could not read the components: %v", err)
+	}
+	if len(names) == 0 {
+		return cache.Components
+	}
+
+	var r []cache.Component
+	for _, name := range names {
+		component, ok := cache.Find(name)
+		if !ok {
+			log.Fatalf("Unknown cache '%v', expected one of %v", name, strings.Join(componentNames(), ", "))
+		}
+		r = append(r, component)
+	}
+	return r
+}
+
+func entries(cmd *cobra.Command) []cache.Entry {
+	r, err := cache.Entries(selected(cmd))
+	if err != nil {
+		log.Fatalf("Could not list the caches: %v", err)
+	}
+	return r
+}
+
+// parseAge parses durations such as 12h, with a d suffix for days
+func parseAge(value string) (time.Duration, error) {
+	if strings.HasSuffix(value, "d") {
+		n, err := strconv.Atoi(strings.TrimSuffix(value, "d"))
+		if err != nil {
+			return 0, fmt.Errorf("'%v' is not a number of days", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(value)
+}
@@ -0,0 +1,15 @@
+package cache
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var clearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove every cached file. They are downloaded again when needed",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		setup()
+		remove(entries(cmd))
+	},
+}
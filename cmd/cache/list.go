@@ -0,0 +1,26 @@
+package cache
+
+import (
+	"fmt"
+	"github.com/satisfactorymodding/SMEI/lib/plan"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the cached files with their size and when they were last modified",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		setup()
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "CACHE\tSIZE\tMODIFIED\tPATH")
+		for _, entry := range entries(cmd) {
+			fmt.Fprintf(w, "%v\t%v\t%v\t%v\n", entry.Component, plan.FormatSize(entry.Size), entry.Modified.Format("2006-01-02 15:04"), entry.Path)
+		}
+		w.Flush()
+	},
+}
@@ -0,0 +1,57 @@
+package cache
+
+import (
+	"github.com/satisfactorymodding/SMEI/lib/cache"
+	"github.com/satisfactorymodding/SMEI/lib/cfmt"
+	"github.com/satisfactorymodding/SMEI/lib/plan"
+	"log"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	pruneCmd.Flags().String("older-than", "30d", "Remove the entries not modified for this long, such as 30d or 12h")
+}
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove the cached files that were not modified for a while",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		setup()
+
+		value, _ := cmd.Flags().GetString("older-than")
+		age, err := parseAge(value)
+		if err != nil {
+			log.Fatalf("Invalid --older-than: %v", err)
+		}
+
+		cutoff := time.Now().Add(-age)
+		var old []cache.Entry
+		for _, entry := range entries(cmd) {
+			if entry.Modified.Before(cutoff) {
+				old = append(old, entry)
+			}
+		}
+		remove(old)
+	},
+}
+
+func remove(entries []cache.Entry) {
+	if len(entries) == 0 {
+		cfmt.Sequence.Println("Nothing to remove")
+		return
+	}
+
+	var freed int64
+	for _, entry := range entries {
+		err := cache.Remove(entry)
+		if err != nil {
+			log.Fatalf("Could not remove '%v': %v", entry.Path, err)
+		}
+		cfmt.Sequence.Printf("Removed '%v'\n", entry.Path)
+		freed += entry.Size
+	}
+	cfmt.Sequence.Printf("Freed %v\n", plan.FormatSize(freed))
+}
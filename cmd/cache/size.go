@@ -0,0 +1,34 @@
+package cache
+
+import (
+	"fmt"
+	"github.com/satisfactorymodding/SMEI/lib/plan"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var sizeCmd = &cobra.Command{
+	Use:   "size",
+	Short: "Show how much space each cache uses",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		setup()
+
+		sizes := map[string]int64{}
+		var total int64
+		for _, entry := range entries(cmd) {
+			sizes[entry.Component] += entry.Size
+			total += entry.Size
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "CACHE\tSIZE\tPATH")
+		for _, component := range selected(cmd) {
+			fmt.Fprintf(w, "%v\t%v\t%v\n", component.Name, plan.FormatSize(sizes[component.Name]), component.Dir())
+		}
+		fmt.Fprintf(w, "total\t%v\t\n", plan.FormatSize(total))
+		w.Flush()
+	},
+}
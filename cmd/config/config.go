@@ -1,22 +1,48 @@
 package config
 
 import (
+	"github.com/satisfactorymodding/SMEI/cmd/config/github"
+	"github.com/satisfactorymodding/SMEI/cmd/config/password"
 	"github.com/satisfactorymodding/SMEI/cmd/config/wwise"
-	"github.com/satisfactorymodding/SMEI/lib/cfmt"
+	"github.com/satisfactorymodding/SMEI/config"
 	"github.com/satisfactorymodding/SMEI/lib/cmdhelp"
+	"log"
 
 	"github.com/spf13/cobra"
 )
 
 var Cmd = &cobra.Command{
 	Use:   "config",
-	Short: "UNIMPLEMENTED Configure SMEI",
+	Short: "Configure SMEI",
+	Long:  "Configure SMEI. The config file is stored in '%APPDATA%\\SMEI\\'",
 	Run: func(cmd *cobra.Command, args []string) {
-		cfmt.Sequence.Println("This command is not yet implemented. Config file is stored at '%APPDATA%\\SMEI\\'")
 		cmdhelp.PrintHelp(cmd)
 	},
 }
 
 func init() {
-	Cmd.AddCommand(wwise.Cmd)
+	Cmd.AddCommand(wwise.Cmd, github.Cmd, password.Cmd, listCmd, getCmd, setCmd, unsetCmd, editCmd)
+}
+
+func setup() {
+	err := config.Setup()
+	if err != nil {
+		log.Fatalf("Could not set up the config: %v", err)
+	}
+}
+
+func findKey(name string) config.Key {
+	key, ok := config.FindKey(name)
+	if !ok {
+		log.Fatalf("Unknown config key '%v'. Run 'smei config list' to see the known keys", name)
+	}
+	return key
+}
+
+// displayValue hides secrets and internal values
+func displayValue(key config.Key, value interface{}) interface{} {
+	if (key.Secret || key.Internal) && value != nil && value != "" {
+		return "<secret>"
+	}
+	return value
 }
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"github.com/satisfactorymodding/SMEI/config"
+	"github.com/satisfactorymodding/SMEI/lib/cfmt"
+	"log"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var editCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Open the config file in $EDITOR",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		setup()
+
+		editor := os.Getenv("EDITOR")
+		if editor == "" {
+			editor = "vi"
+			if runtime.GOOS == "windows" {
+				editor = "notepad"
+			}
+		}
+
+		file := viper.ConfigFileUsed()
+		command := exec.Command(editor, file)
+		command.Stdin = os.Stdin
+		command.Stdout = os.Stdout
+		command.Stderr = os.Stderr
+		err := command.Run()
+		if err != nil {
+			log.Fatalf("Could not run the editor '%v': %v", editor, err)
+		}
+
+		err = viper.ReadInConfig()
+		if err != nil {
+			log.Fatalf("The config file is not valid anymore: %v", err)
+		}
+
+		for _, key := range config.Keys {
+			if key.Secret || key.Internal || !viper.InConfig(key.Name) {
+				continue
+			}
+			_, err = key.Parse(viper.GetString(key.Name))
+			if err != nil {
+				cfmt.Warning.Printf("Invalid value for '%v': %v\n", key.Name, err)
+			}
+		}
+	},
+}
@@ -0,0 +1,20 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var getCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print the value of a config key. Secrets are not shown",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		setup()
+
+		key := findKey(args[0])
+		fmt.Println(displayValue(key, viper.Get(key.Name)))
+	},
+}
@@ -0,0 +1,128 @@
+package github
+
+import (
+	"context"
+	"github.com/satisfactorymodding/SMEI/config"
+	"github.com/satisfactorymodding/SMEI/lib/cfmt"
+	"github.com/satisfactorymodding/SMEI/lib/cmdhelp"
+	"github.com/satisfactorymodding/SMEI/lib/credentials"
+	"github.com/satisfactorymodding/SMEI/lib/env/gh"
+	"github.com/satisfactorymodding/SMEI/lib/env/ue"
+	"github.com/satisfactorymodding/SMEI/lib/report"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	Cmd.AddCommand(loginCmd, statusCmd, logoutCmd)
+}
+
+var Cmd = &cobra.Command{
+	Use:   "github",
+	Short: "Configure GitHub authentication",
+	Run: func(cmd *cobra.Command, args []string) {
+		cmdhelp.PrintHelp(cmd)
+	},
+}
+
+func setup() {
+	err := config.Setup()
+	if err != nil {
+		log.Fatalf("Could not set up the config: %v", err)
+	}
+
+	err = credentials.EnsurePassword()
+	if err != nil {
+		log.Fatalf("Could not get a password: %v", err)
+	}
+}
+
+var loginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Authenticate with GitHub, replacing the stored token",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		setup()
+		if credentials.IsNonInteractive() {
+			log.Fatalf("Logging in to GitHub needs a browser. Provide a token with --%v or %v instead", credentials.GHTokenInput.Flag, credentials.GHTokenInput.Env)
+		}
+
+		_, err := gh.Login()
+		if err != nil {
+			log.Fatalf("Could not log in: %v", err)
+		}
+		cfmt.Sequence.Println("Logged in to GitHub")
+	},
+}
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the GitHub user, the token scopes and whether the Unreal Engine repository can be accessed. Exits with an error on problems",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		setup()
+
+		token, err := gh.StoredToken()
+		if err != nil {
+			log.Fatalf("Could not get the token: %v", err)
+		}
+		if token == "" {
+			cfmt.Warning.Println("Not logged in. Run 'smei config github login'")
+			os.Exit(1)
+		}
+
+		ctx := context.Background()
+		client := gh.NewClient(ctx, string(token))
+		status, err := gh.GetStatus(ctx, client)
+		if gh.IsUnauthorized(err) {
+			cfmt.Error.Println("The token expired or was revoked. Run 'smei config github login'")
+			os.Exit(1)
+		}
+		if err != nil {
+			log.Fatalf("Could not get the status: %v", err)
+		}
+
+		cfmt.Sequence.Printf("Logged in as %v\n", status.Login)
+		failed := false
+		if status.Scopes == nil {
+			cfmt.Sequence.Println("The token does not report its scopes")
+		} else {
+			cfmt.Sequence.Printf("Token scopes: %v\n", strings.Join(status.Scopes, ", "))
+		}
+		if !status.HasRequiredScope() {
+			cfmt.Error.Printf("The token is missing the '%v' scope. Run 'smei config github login'\n", gh.RequiredScope)
+			failed = true
+		}
+
+		err = ue.EnsureGithubAccess(ctx, client)
+		if err != nil {
+			cfmt.Error.Printf("%v\n", err)
+			cfmt.Warning.Println(report.HintOf(err))
+			failed = true
+		} else {
+			cfmt.Sequence.Println("The Unreal Engine repository can be accessed")
+		}
+
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+var logoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "Remove the stored GitHub token",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		setup()
+
+		err := gh.Logout()
+		if err != nil {
+			log.Fatalf("Could not log out: %v", err)
+		}
+		cfmt.Sequence.Println("Logged out of GitHub. The token can also be revoked at https://github.com/settings/applications")
+	},
+}
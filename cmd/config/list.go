@@ -0,0 +1,32 @@
+package config
+
+import (
+	"fmt"
+	"github.com/satisfactorymodding/SMEI/config"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List every config key with its value, source and default",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		setup()
+
+		defaults := config.Defaults()
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "KEY\tVALUE\tSOURCE\tDEFAULT")
+		for _, key := range config.Keys {
+			fmt.Fprintf(w, "%v\t%v\t%v\t%v\n",
+				key.Name,
+				displayValue(key, viper.Get(key.Name)),
+				key.Source(),
+				displayValue(key, defaults[key.Name]))
+		}
+		w.Flush()
+	},
+}
@@ -0,0 +1,44 @@
+package password
+
+import (
+	"github.com/satisfactorymodding/SMEI/config"
+	"github.com/satisfactorymodding/SMEI/lib/cfmt"
+	"github.com/satisfactorymodding/SMEI/lib/cmdhelp"
+	"github.com/satisfactorymodding/SMEI/lib/credentials"
+	"log"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	Cmd.AddCommand(changeCmd)
+}
+
+var Cmd = &cobra.Command{
+	Use:   "password",
+	Short: "Manage the SMEI password",
+	Run: func(cmd *cobra.Command, args []string) {
+		cmdhelp.PrintHelp(cmd)
+	},
+}
+
+var changeCmd = &cobra.Command{
+	Use:   "change",
+	Short: "Change the SMEI password, keeping the stored credentials",
+	Run: func(cmd *cobra.Command, args []string) {
+		err := config.Setup()
+		if err != nil {
+			log.Fatalf("Could not set up the config: %v", err)
+		}
+
+		err = credentials.ChangePassword()
+		if err == config.InvalidPassword {
+			log.Fatalf("The current password is invalid. No changes were made")
+		}
+		if err != nil {
+			log.Fatalf("Could not change the password: %v", err)
+		}
+
+		cfmt.Sequence.Println("Password changed")
+	},
+}
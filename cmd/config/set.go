@@ -0,0 +1,54 @@
+package config
+
+import (
+	"github.com/satisfactorymodding/SMEI/config"
+	"github.com/satisfactorymodding/SMEI/lib/cfmt"
+	"github.com/satisfactorymodding/SMEI/lib/credentials"
+	"github.com/satisfactorymodding/SMEI/lib/secret"
+	"log"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var setCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Set a config key. Secrets are stored through the secret store, never in plain text",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		setup()
+
+		key := findKey(args[0])
+		if key.Internal {
+			log.Fatalf("'%v' is managed by SMEI and cannot be set", key.Name)
+		}
+
+		value, err := key.Parse(args[1])
+		if err != nil {
+			log.Fatalf("Invalid value for '%v': %v", key.Name, err)
+		}
+
+		if key.Secret {
+			err = credentials.EnsurePassword()
+			if err != nil {
+				log.Fatalf("Could not get a password: %v", err)
+			}
+			err = config.SetSecretString(key.Name, secret.String(args[1]))
+			if err != nil {
+				log.Fatalf("Could not set the secret: %v", err)
+			}
+		} else {
+			viper.Set(key.Name, value)
+		}
+
+		err = config.Write()
+		if err != nil {
+			log.Fatalf("Could not persist the config changes: %v", err)
+		}
+
+		if key.Source() == config.SourceEnv {
+			cfmt.Warning.Printf("%v is set and overrides the config file\n", key.EnvName())
+		}
+		cfmt.Sequence.Printf("'%v' set to '%v'\n", key.Name, displayValue(key, value))
+	},
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"github.com/satisfactorymodding/SMEI/config"
+	"github.com/satisfactorymodding/SMEI/lib/cfmt"
+	"log"
+
+	"github.com/spf13/cobra"
+)
+
+var unsetCmd = &cobra.Command{
+	Use:   "unset <key>",
+	Short: "Remove a config key from the config file so its default applies again",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		setup()
+
+		key := findKey(args[0])
+		if key.Internal {
+			log.Fatalf("'%v' is managed by SMEI and cannot be unset", key.Name)
+		}
+
+		if key.Secret {
+			err := config.DeleteSecret(key.Name)
+			if err != nil {
+				log.Fatalf("Could not delete the secret: %v", err)
+			}
+		}
+
+		err := config.Unset(key.Name)
+		if err != nil {
+			log.Fatalf("Could not unset '%v': %v", key.Name, err)
+		}
+
+		cfmt.Sequence.Printf("'%v' unset\n", key.Name)
+	},
+}
@@ -1,17 +1,114 @@
 package auth
 
 import (
+	"github.com/satisfactorymodding/SMEI/config"
 	"github.com/satisfactorymodding/SMEI/lib/cfmt"
 	"github.com/satisfactorymodding/SMEI/lib/cmdhelp"
+	"github.com/satisfactorymodding/SMEI/lib/credentials"
+	"log"
+	"os"
 
 	"github.com/spf13/cobra"
 )
 
+func init() {
+	Cmd.AddCommand(loginCmd, statusCmd, logoutCmd, testCmd)
+}
+
 var Cmd = &cobra.Command{
 	Use:   "auth",
-	Short: "UNIMPLEMENTED Configure Wwise authentication",
+	Short: "Configure Wwise authentication",
 	Run: func(cmd *cobra.Command, args []string) {
-		cfmt.Sequence.Println("This command is not yet implemented")
 		cmdhelp.PrintHelp(cmd)
 	},
 }
+
+func setup() {
+	err := config.Setup()
+	if err != nil {
+		log.Fatalf("Could not set up the config: %v", err)
+	}
+
+	err = credentials.EnsurePassword()
+	if err != nil {
+		log.Fatalf("Could not get a password: %v", err)
+	}
+}
+
+var loginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Enter and store your Audiokinetic credentials, replacing the stored ones",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		setup()
+
+		err := credentials.WwiseLogin(credentials.NewWwiseAuthenticator())
+		if err != nil {
+			log.Fatalf("Could not log in: %v", err)
+		}
+		cfmt.Sequence.Println("Logged in to Audiokinetic")
+	},
+}
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the stored Audiokinetic account and whether it can log in",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		setup()
+
+		status, err := credentials.GetWwiseStatus(credentials.NewWwiseAuthenticator())
+		if err != nil {
+			log.Fatalf("Could not get the status: %v", err)
+		}
+
+		if !status.LoggedIn {
+			cfmt.Warning.Println("Not logged in. Run 'smei config wwise auth login'")
+			return
+		}
+		cfmt.Sequence.Printf("Logged in as %v\n", status.MaskedEmail)
+		if status.AuthError != nil {
+			cfmt.Error.Printf("Authentication fails: %v. Run 'smei config wwise auth login' to fix your credentials\n", status.AuthError)
+			return
+		}
+		cfmt.Sequence.Println("Authentication succeeds")
+	},
+}
+
+var logoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "Remove the stored Audiokinetic credentials",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		setup()
+
+		err := credentials.WwiseLogout()
+		if err != nil {
+			log.Fatalf("Could not log out: %v", err)
+		}
+		cfmt.Sequence.Println("Logged out of Audiokinetic")
+	},
+}
+
+var testCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Check that the stored Audiokinetic credentials can log in. Exits with an error if not",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		setup()
+
+		status, err := credentials.GetWwiseStatus(credentials.NewWwiseAuthenticator())
+		if err != nil {
+			log.Fatalf("Could not test the credentials: %v", err)
+		}
+		if !status.LoggedIn {
+			cfmt.Error.Println("No credentials are stored")
+			os.Exit(1)
+		}
+		if status.AuthError != nil {
+			cfmt.Error.Printf("Authentication failed: %v\n", status.AuthError)
+			os.Exit(1)
+		}
+		cfmt.Sequence.Println("Authentication succeeded")
+	},
+}
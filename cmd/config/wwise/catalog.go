@@ -0,0 +1,64 @@
+package wwise
+
+import (
+	"github.com/satisfactorymodding/SMEI/config"
+	"github.com/satisfactorymodding/SMEI/lib/cfmt"
+	"github.com/satisfactorymodding/SMEI/lib/credentials"
+	"github.com/satisfactorymodding/SMEI/lib/env/wwise"
+	"log"
+
+	"github.com/mircearoata/wwise-cli/lib/wwise/client"
+	"github.com/pkg/errors"
+)
+
+func setup() {
+	err := config.Setup()
+	if err != nil {
+		log.Fatalf("Could not set up the config: %v", err)
+	}
+}
+
+// getCatalog fetches the catalog, falling back to the cached one if that fails or if offline is set
+func getCatalog(offline bool) *wwise.Catalog {
+	if !offline {
+		catalog, err := fetchCatalog()
+		if err == nil {
+			return catalog
+		}
+		cfmt.Warning.Printf("Could not fetch the Wwise versions, using the cached ones: %v\n", err)
+	}
+
+	catalog, err := wwise.LoadCatalog()
+	if err != nil {
+		log.Fatalf("Could not load the cached Wwise versions: %v", err)
+	}
+	if catalog == nil {
+		log.Fatalf("The Wwise versions were never fetched. Run 'smei config wwise versions' while online")
+	}
+	cfmt.Warning.Printf("Using the Wwise versions cached on %v\n", catalog.Fetched.Format("2006-01-02 15:04"))
+	return catalog
+}
+
+func fetchCatalog() (*wwise.Catalog, error) {
+	err := credentials.EnsurePassword()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get a password")
+	}
+
+	auth, err := credentials.GetWwiseCredentials()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get the Wwise credentials")
+	}
+
+	wwiseClient := client.NewWwiseClient()
+	err = wwiseClient.Authenticate(string(auth.Email), string(auth.Password))
+	if err != nil {
+		return nil, errors.Wrap(err, "authentication error. check your Wwise credentials")
+	}
+
+	catalog, err := wwise.FetchCatalog(wwiseClient)
+	if err != nil {
+		return nil, err
+	}
+	return catalog, catalog.Save()
+}
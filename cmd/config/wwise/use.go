@@ -0,0 +1,72 @@
+package wwise
+
+import (
+	"github.com/satisfactorymodding/SMEI/config"
+	"github.com/satisfactorymodding/SMEI/lib/cfmt"
+	"github.com/satisfactorymodding/SMEI/lib/env/ue"
+	"log"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	useCmd.Flags().Bool("offline", false, "Validate against the cached versions without contacting Audiokinetic")
+}
+
+var useCmd = &cobra.Command{
+	Use:   "use <sdk-version> [integration-version]",
+	Short: "Select the Wwise SDK and Unreal integration versions. The newest integration for the SDK is used if none is given",
+	Args:  cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		setup()
+
+		offline, _ := cmd.Flags().GetBool("offline")
+		catalog := getCatalog(offline)
+
+		sdkVersion := args[0]
+		var integrationVersion string
+		if len(args) > 1 {
+			integrationVersion = args[1]
+		} else {
+			integrations := catalog.IntegrationsFor(sdkVersion)
+			if len(integrations) == 0 {
+				log.Fatalf("No Unreal integration version is built for SDK %v. Give one explicitly", sdkVersion)
+			}
+			integrationVersion = integrations[0].ID
+		}
+
+		warnings, err := catalog.Validate(sdkVersion, integrationVersion, unrealVersion())
+		if err != nil {
+			log.Fatalf("Invalid Wwise versions: %v", err)
+		}
+		for _, warning := range warnings {
+			cfmt.Warning.Println(warning)
+		}
+
+		// Store the IDs without their product prefix, which the config keys do not accept
+		sdkVersion = catalog.FindSDK(sdkVersion).ID
+		integrationVersion = catalog.FindIntegration(integrationVersion).ID
+		viper.Set(config.WwiseSdkVersion_key, sdkVersion)
+		viper.Set(config.WwiseIntegrationVersion_key, integrationVersion)
+		err = config.Write()
+		if err != nil {
+			log.Fatalf("Could not persist the config changes: %v", err)
+		}
+		cfmt.Sequence.Printf("Using Wwise SDK %v with Unreal integration %v\n", sdkVersion, integrationVersion)
+	},
+}
+
+// unrealVersion returns the major.minor version of the installed engine, or an empty string if it cannot be found
+func unrealVersion() string {
+	info, err := ue.Detect(viper.GetString(config.UEInstallPath_key))
+	if err != nil || info == nil {
+		return ""
+	}
+	parts := strings.SplitN(info.Version, ".", 3)
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[0] + "." + parts[1]
+}
@@ -0,0 +1,62 @@
+package wwise
+
+import (
+	"fmt"
+	"github.com/satisfactorymodding/SMEI/config"
+	"github.com/satisfactorymodding/SMEI/lib/env/wwise"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	versionsCmd.Flags().Bool("offline", false, "List the cached versions without contacting Audiokinetic")
+	versionsCmd.Flags().Bool("all", false, "Include versions that are not stable releases")
+}
+
+var versionsCmd = &cobra.Command{
+	Use:   "versions",
+	Short: "List the Wwise SDK and Unreal integration versions available from Audiokinetic",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		setup()
+
+		offline, _ := cmd.Flags().GetBool("offline")
+		all, _ := cmd.Flags().GetBool("all")
+		catalog := getCatalog(offline)
+
+		fmt.Println("SDK versions:")
+		printVersions(catalog.SDK, viper.GetString(config.WwiseSdkVersion_key), all, false)
+		fmt.Println()
+		fmt.Println("Unreal integration versions:")
+		printVersions(catalog.Integration, viper.GetString(config.WwiseIntegrationVersion_key), all, true)
+	},
+}
+
+func printVersions(versions []wwise.Version, current string, all bool, integration bool) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if integration {
+		fmt.Fprintln(w, "  VERSION\tNAME\tSDK\tUNREAL")
+	} else {
+		fmt.Fprintln(w, "  VERSION\tNAME")
+	}
+
+	for _, v := range versions {
+		if !v.Stable && !all && v.ID != current {
+			continue
+		}
+		marker := " "
+		if v.ID == current {
+			marker = "*"
+		}
+		if integration {
+			fmt.Fprintf(w, "%v %v\t%v\t%v\t%v\n", marker, v.ID, v.Name, v.SdkVersion, strings.Join(v.UnrealVersions, ", "))
+		} else {
+			fmt.Fprintf(w, "%v %v\t%v\n", marker, v.ID, v.Name)
+		}
+	}
+	w.Flush()
+}
@@ -1,17 +1,20 @@
 package wwise
 
 import (
-	"github.com/satisfactorymodding/SMEI/lib/cfmt"
+	"github.com/satisfactorymodding/SMEI/cmd/config/wwise/auth"
 	"github.com/satisfactorymodding/SMEI/lib/cmdhelp"
 
 	"github.com/spf13/cobra"
 )
 
+func init() {
+	Cmd.AddCommand(auth.Cmd, versionsCmd, useCmd)
+}
+
 var Cmd = &cobra.Command{
 	Use:   "wwise",
-	Short: "UNIMPLEMENTED Configure Wwise",
+	Short: "Configure Wwise",
 	Run: func(cmd *cobra.Command, args []string) {
-		cfmt.Sequence.Println("This command is not yet implemented")
 		cmdhelp.PrintHelp(cmd)
 	},
 }
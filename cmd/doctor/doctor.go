@@ -0,0 +1,117 @@
+package doctor
+
+import (
+	"fmt"
+	"github.com/satisfactorymodding/SMEI/config"
+	"github.com/satisfactorymodding/SMEI/lib/cfmt"
+	"github.com/satisfactorymodding/SMEI/lib/env/project"
+	"github.com/satisfactorymodding/SMEI/lib/env/scan"
+	"github.com/satisfactorymodding/SMEI/lib/report"
+	"log"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	flags := Cmd.Flags()
+
+	flags.StringP("target", "t", "", "Where the project is installed. Project checks are skipped if empty")
+}
+
+var Cmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the modding environment and report what is missing",
+	Run: func(cmd *cobra.Command, args []string) {
+		run := report.Start()
+
+		err := config.Setup()
+		if err != nil {
+			log.Fatalf("Could not set up the config: %v", err)
+		}
+
+		err = viper.BindPFlags(cmd.LocalNonPersistentFlags())
+		if err != nil {
+			log.Fatalf("Could not bind the CLI flags to the configuration system: %v", err)
+		}
+
+		target := viper.GetString("target")
+
+		cfmt.Sequence.Println("Scanning the modding environment")
+		info, err := scan.Scan(target)
+		if err != nil {
+			run.Record("scan", report.Failed, err, "")
+			run.Finish(info)
+			log.Fatalf("Could not scan the environment: %v", err)
+		}
+
+		d := doctor{run: run}
+
+		if info.UE != nil {
+			d.found("ue", "Unreal Engine %v at '%v'", info.UE.Version, info.UE.Location)
+		} else {
+			d.missing("ue", "Run 'smei install' to install it", "Unreal Engine not found (expected at '%v')", viper.GetString(config.UEInstallPath_key))
+		}
+
+		if info.VS != nil {
+			d.found("vs", "Visual Studio %v at '%v'", info.VS.Version, info.VS.Location)
+			missingComponents := info.VS.MissingComponents()
+			if len(missingComponents) == 0 {
+				d.found("vs-components", "All required Visual Studio components are installed")
+			}
+			for _, component := range missingComponents {
+				d.missing("vs-components", "Add it with the Visual Studio Installer or run 'smei install'", "Visual Studio component '%v' is not installed", component)
+			}
+		} else {
+			d.missing("vs", "Run 'smei install' to install it", "Visual Studio 2022 not found")
+		}
+
+		if target != "" {
+			if info.Project != nil {
+				d.found("project", "Modding project at '%v'", info.Project.Location)
+				d.reportGit(info.Project.Git)
+			} else {
+				d.missing("project", "Run 'smei install --target' to clone it", "No modding project in '%v'", target)
+			}
+		}
+
+		run.Finish(info)
+
+		if run.Failed() {
+			cfmt.Warning.Println("Problems were found. Run 'smei install' to fix them")
+			os.Exit(1)
+		}
+		cfmt.Sequence.Println("No problems found")
+	},
+}
+
+type doctor struct {
+	run *report.Run
+}
+
+func (d doctor) reportGit(git *project.GitInfo) {
+	if git == nil {
+		d.missing("git", "Delete the project and run 'smei install' to clone it again", "The project is not a git repository")
+		return
+	}
+
+	d.found("git", "On branch '%v' at commit %v", git.Branch, git.Commit)
+	if !git.UpToDate {
+		message := "The project is not up to date with the remote (or it could not be reached)"
+		cfmt.Warning.Printf("  %v\n", message)
+		d.run.Record("git-up-to-date", report.Warning, errors.New(message), "Pull the latest changes with git")
+	}
+}
+
+func (d doctor) found(step, format string, a ...interface{}) {
+	fmt.Printf("  [OK] "+format+"\n", a...)
+	d.run.Record(step, report.OK, nil, "")
+}
+
+func (d doctor) missing(step, hint, format string, a ...interface{}) {
+	message := fmt.Sprintf(format, a...)
+	cfmt.Error.Printf("  [MISSING] %v\n", message)
+	d.run.Record(step, report.Failed, errors.New(message), hint)
+}
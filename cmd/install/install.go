@@ -4,12 +4,15 @@ import (
 	"fmt"
 	integrate "github.com/satisfactorymodding/SMEI/cmd/install/wwise"
 	"github.com/satisfactorymodding/SMEI/config"
+	"github.com/satisfactorymodding/SMEI/lib/cache"
 	"github.com/satisfactorymodding/SMEI/lib/cfmt"
 	"github.com/satisfactorymodding/SMEI/lib/credentials"
 	"github.com/satisfactorymodding/SMEI/lib/elevate"
-	"github.com/satisfactorymodding/SMEI/lib/env/project"
 	"github.com/satisfactorymodding/SMEI/lib/env/ue"
-	"github.com/satisfactorymodding/SMEI/lib/env/vs"
+	"github.com/satisfactorymodding/SMEI/lib/pipeline"
+	"github.com/satisfactorymodding/SMEI/lib/plan"
+	"github.com/satisfactorymodding/SMEI/lib/report"
+	"github.com/satisfactorymodding/SMEI/lib/runlog"
 	"log"
 	"os"
 	"os/signal"
@@ -25,6 +28,12 @@ func init() {
 	flags.BoolP("local", "l", false, "Install dependencies in the target directory instead of globally")
 	flags.StringP("target", "t", "", "Where to install the project")
 	flags.BoolP("nonelevated", "e", false, "Choose whether to elevate the process or not. UE installation requires privileges")
+	flags.Bool("resume", false, "Skip the steps a previous install of this target completed")
+	flags.String("restart-from", "", "Resume, but run this step and the ones after it again")
+	flags.StringSlice("only", nil, "Only run these steps")
+	flags.StringSlice("skip", nil, "Do not run these steps")
+	flags.Bool("dry-run", false, "Print what the install would download, write and run without making any changes")
+	flags.String("ue-version", "", "Unreal Engine release tag to install, overriding the "+config.UEReleaseTag_key+" config key. See 'smei ue releases'")
 
 	requiredFlags := []string{"target"}
 	for _, flag := range requiredFlags {
@@ -41,81 +50,118 @@ var Cmd = &cobra.Command{
 	Use:   "install",
 	Short: "Install a modding environment, or components of one",
 	Run: func(cmd *cobra.Command, args []string) {
+		run := report.Start()
+		var summaryData interface{}
+		dryRun := false
 		defer func() {
 			v := recover()
 			if v != nil {
+				if !run.Failed() {
+					// The panic did not come from a failed step, the summary must not report success
+					run.Record("install", report.Failed, fmt.Errorf("%v", v), "")
+				}
 				fmt.Println(v)
 			}
+			run.Finish(summaryData)
+			if (v != nil || run.Failed()) && runlog.Current() != "" {
+				cfmt.Warning.Printf("The log of this run is in '%v'. Attach it when reporting the problem\n", runlog.Current())
+			}
+			if report.IsJSON() || dryRun {
+				if run.Failed() {
+					os.Exit(1)
+				}
+				return
+			}
 			fmt.Println("Use ctrl+C to close this window")
 			c := make(chan os.Signal)
 			signal.Notify(c, os.Interrupt)
 			<-c
 		}()
 
-		err := config.Setup()
-
-		err = viper.BindPFlags(cmd.Flags())
+		err := viper.BindPFlags(cmd.LocalNonPersistentFlags())
 		if err != nil {
 			log.Panicf("Could not bind the CLI flags to the configuration system: %v", err)
 		}
 
-		doElevate := !viper.GetBool("nonelevated")
+		dryRun = viper.GetBool("dry-run")
+		var p *plan.Plan
+		if dryRun {
+			p = plan.New()
+			summaryData = p
+			err = config.SetupReadOnly()
+		} else {
+			err = config.Setup()
+		}
+		if err != nil {
+			log.Panicf("Could not set up the config: %v", err)
+		}
+
+		ue.ReleaseTagOverride = viper.GetString("ue-version")
+
+		doElevate := !viper.GetBool("nonelevated") && !dryRun
 		if doElevate {
 			elevate.EnsureElevatedFinal()
 		}
 
-		if !config.HasPassword() {
-			err = credentials.AskForPassword()
-			if err != nil {
-				log.Panicf("Could not get a password: %v", err)
+		err = run.Step("password", func() error {
+			if credentials.SecretsProvided() {
+				return nil
 			}
-		}
-
-		// Collect Wwise credentials in advance of any downloading steps so no interactivity is required mid-install
-		wwiseCredentials, err := credentials.GetWwiseCredentials()
+			if dryRun {
+				// Nothing is prompted for in a dry run, only the credentials available without the password are used
+				if config.Secrets().NeedsPassword() {
+					p.Note("The SMEI password would be asked for to read the stored credentials")
+				}
+				return nil
+			}
+			return credentials.EnsurePassword()
+		})
 		if err != nil {
-			log.Panicf("Could not get the Wwise credentials: %v", err)
+			log.Panicf("Could not get a password: %v", err)
 		}
 
 		local := viper.GetBool("local")
 		target := viper.GetString("target")
 
 		cfmt.Sequence.Println("Checking SMEI cached files")
-		installerDir := os.TempDir()
-		if viper.GetBool(config.PreserveUEInstaller_key) {
-			installerDir = filepath.Join(config.ConfigDir, ue.CacheFolder)
-		}
+		installerDir := cache.Dir(cache.UE)
 
-		// If lacking github credentials, this will prompt for them. Not needed if the installer files don't need to be downloaded.
-		// No further user interaction should be required past this point.
-		cfmt.Sequence.Println("Analyzing Unreal Engine install")
 		UEInstallDir := viper.GetString(config.UEInstallPath_key)
-		fmt.Printf("Expecting UE install dir to be at '%v'\n", UEInstallDir)
+		cfmt.Sequence.Printf("Expecting UE install dir to be at '%v'\n", UEInstallDir)
 		if local {
 			UEInstallDir = filepath.Join(target, config.UEFolderName)
 		}
-		avoidUeReinstall := viper.GetBool(config.UESkipReinstall_key)
-		err = ue.Install(UEInstallDir, installerDir, avoidUeReinstall)
-		if err != nil {
-			log.Panicf("Could not install the Unreal Engine: %v", err)
-		}
 
-		cfmt.Sequence.Println("Installing Visual Studio...")
 		VSInstallPath := viper.GetString(config.VSInstallPath_key)
 		if local {
 			VSInstallPath = filepath.Join(target, "VS22")
 		}
-		avoidVsReinstall := viper.GetBool(config.VSSkipReinstall_key)
-		err = vs.Install(VSInstallPath, avoidVsReinstall)
+
+		runner := &pipeline.Runner{
+			Steps:  pipeline.Registered(),
+			Only:   viper.GetStringSlice("only"),
+			Skip:   viper.GetStringSlice("skip"),
+			Report: run,
+		}
+		runner.Journal, runner.Resume, err = loadJournal(runner, target, viper.GetBool("resume"), viper.GetString("restart-from"), dryRun)
 		if err != nil {
-			log.Panicf("Could not install Visual Studio: %v", err)
+			log.Panicf("Could not load the install journal: %v", err)
 		}
 
-		cfmt.Sequence.Println("Installing modding project...")
-		err = project.Install(target, UEInstallDir, *wwiseCredentials)
-
+		err = runner.Run(&pipeline.Context{
+			Target:        target,
+			UEInstallDir:  UEInstallDir,
+			InstallerDir:  installerDir,
+			VSInstallPath: VSInstallPath,
+			Plan:          p,
+		})
 		if err != nil {
-			log.Panicf("Could not install the project: %v", err)
+			log.Panicf("Could not install the modding environment: %v", err)
+		}
+
+		if dryRun && !report.IsJSON() {
+			cfmt.Sequence.Println("Dry run complete. The install would do the following:")
+			p.Print()
 		}
 	},
 }
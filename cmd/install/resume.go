@@ -0,0 +1,41 @@
+package install
+
+import (
+	"github.com/satisfactorymodding/SMEI/lib/journal"
+	"github.com/satisfactorymodding/SMEI/lib/pipeline"
+
+	"github.com/pkg/errors"
+)
+
+// loadJournal loads the journal of target and prepares it for the run. Returns whether journaled steps should be skipped
+// The journal is not written to if dryRun is set
+func loadJournal(runner *pipeline.Runner, target string, resume bool, restartFrom string, dryRun bool) (*journal.Journal, bool, error) {
+	j, err := journal.Load(target)
+	if err != nil {
+		return nil, false, err
+	}
+	if dryRun {
+		j.ReadOnly()
+	}
+
+	if restartFrom != "" {
+		ordered, err := pipeline.Order(runner.Steps)
+		if err != nil {
+			return nil, false, errors.Wrap(err, "could not order the steps")
+		}
+		err = j.RestartFrom(pipeline.Names(ordered), restartFrom)
+		if err != nil {
+			return nil, false, err
+		}
+		return j, true, nil
+	}
+
+	if !resume {
+		err = j.Reset()
+		if err != nil {
+			return nil, false, errors.Wrap(err, "could not reset the journal")
+		}
+	}
+
+	return j, resume, nil
+}
@@ -0,0 +1,21 @@
+package install
+
+import (
+	"github.com/satisfactorymodding/SMEI/lib/env/project"
+	"github.com/satisfactorymodding/SMEI/lib/env/ue"
+	"github.com/satisfactorymodding/SMEI/lib/env/vs"
+	"github.com/satisfactorymodding/SMEI/lib/pipeline"
+)
+
+// New steps are added here. Steps without dependencies between them run in this order
+func init() {
+	pipeline.Register(
+		ue.Step{},
+		vs.Step{},
+		project.CloneStep{},
+		project.WwiseStep{},
+		project.ProjectFilesStep{},
+		project.BuildStep{Shipping: false},
+		project.BuildStep{Shipping: true},
+	)
+}
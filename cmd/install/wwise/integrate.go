@@ -44,7 +44,7 @@ var Cmd = &cobra.Command{
 			<-c
 		}()
 
-		err := viper.BindPFlags(cmd.Flags())
+		err := viper.BindPFlags(cmd.LocalNonPersistentFlags())
 		if err != nil {
 			log.Panicf("Could not bind the CLI flags to the configuration system: %v", err)
 		}
@@ -53,11 +53,9 @@ var Cmd = &cobra.Command{
 
 		err = config.Setup()
 
-		if !config.HasPassword() {
-			err = credentials.AskForPassword()
-			if err != nil {
-				log.Panicf("Could not get a password: %v", err)
-			}
+		err = credentials.EnsurePassword()
+		if err != nil {
+			log.Panicf("Could not get a password: %v", err)
 		}
 
 		wwiseCredentials, err := credentials.GetWwiseCredentials()
@@ -67,7 +65,7 @@ var Cmd = &cobra.Command{
 
 		uprojectPath := project.TargetPathToUProjectPath(target, false)
 		cfmt.Sequence.Printf("Integrating Wwise into '%s'...\n", uprojectPath)
-		err = project.InstallWWise(uprojectPath, *wwiseCredentials)
+		err = project.InstallWWise(uprojectPath, *wwiseCredentials, nil)
 
 		if err != nil {
 			log.Panicf("Could not integrate wwise the project: %v", err)
@@ -0,0 +1,98 @@
+package logs
+
+import (
+	"fmt"
+	"github.com/satisfactorymodding/SMEI/lib/cfmt"
+	"github.com/satisfactorymodding/SMEI/lib/cmdhelp"
+	"github.com/satisfactorymodding/SMEI/lib/plan"
+	"github.com/satisfactorymodding/SMEI/lib/runlog"
+	"io"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	for _, cmd := range []*cobra.Command{showCmd, lastCmd} {
+		cmd.Flags().Bool("path", false, "Print the path of the log instead of its content, such as to attach it to a bug report")
+	}
+	Cmd.AddCommand(listCmd, showCmd, lastCmd)
+}
+
+var Cmd = &cobra.Command{
+	Use:   "logs",
+	Short: "Read the logs of previous runs",
+	Long: "Read the logs of previous runs. Every run writes the steps, the external commands with their output and the errors to a log in '" + runlog.Dir() + "'. " +
+		"Only the newest logs are kept",
+	Run: func(cmd *cobra.Command, args []string) {
+		cmdhelp.PrintHelp(cmd)
+	},
+}
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the logs, newest first",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		logs, err := runlog.List()
+		if err != nil {
+			log.Fatalf("Could not list the logs: %v", err)
+		}
+		if len(logs) == 0 {
+			cfmt.Sequence.Println("There is no log yet")
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tSIZE\tMODIFIED\tCOMMAND")
+		for _, info := range logs {
+			fmt.Fprintf(w, "%v\t%v\t%v\t%v\n", info.Name, plan.FormatSize(info.Size), info.Modified.Format("2006-01-02 15:04"), info.Command)
+		}
+		w.Flush()
+	},
+}
+
+var showCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Print a log. Names are listed by 'smei logs list'",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		info, err := runlog.Find(args[0])
+		if err != nil {
+			log.Fatalf("Could not find the log: %v", err)
+		}
+		show(cmd, info)
+	},
+}
+
+var lastCmd = &cobra.Command{
+	Use:   "last",
+	Short: "Print the log of the last run",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		info, err := runlog.Last()
+		if err != nil {
+			log.Fatalf("Could not find the log: %v", err)
+		}
+		show(cmd, info)
+	},
+}
+
+func show(cmd *cobra.Command, info runlog.Info) {
+	if path, _ := cmd.Flags().GetBool("path"); path {
+		fmt.Println(info.Path)
+		return
+	}
+
+	f, err := os.Open(info.Path)
+	if err != nil {
+		log.Fatalf("Could not open the log: %v", err)
+	}
+	defer f.Close()
+	_, err = io.Copy(os.Stdout, f)
+	if err != nil {
+		log.Fatalf("Could not print the log: %v", err)
+	}
+}
@@ -1,28 +1,101 @@
 package cmd
 
 import (
+	"github.com/satisfactorymodding/SMEI/cmd/cache"
 	configCmd "github.com/satisfactorymodding/SMEI/cmd/config"
+	"github.com/satisfactorymodding/SMEI/cmd/doctor"
 	"github.com/satisfactorymodding/SMEI/cmd/install"
+	"github.com/satisfactorymodding/SMEI/cmd/logs"
 	"github.com/satisfactorymodding/SMEI/cmd/test"
+	"github.com/satisfactorymodding/SMEI/cmd/ue"
+	"github.com/satisfactorymodding/SMEI/cmd/uninstall"
+	"github.com/satisfactorymodding/SMEI/lib/cfmt"
 	"github.com/satisfactorymodding/SMEI/lib/cmdhelp"
+	"github.com/satisfactorymodding/SMEI/lib/credentials"
+	"github.com/satisfactorymodding/SMEI/lib/progress"
+	"github.com/satisfactorymodding/SMEI/lib/report"
+	"github.com/satisfactorymodding/SMEI/lib/runlog"
+	"os"
+	"strings"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
 var RootCmd = &cobra.Command{
 	Use:   "smei",
 	Short: "Assists in setting up a modding environment for Satisfactory",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		output, err := cmd.Flags().GetString("output")
+		if err != nil {
+			return err
+		}
+		err = report.SetFormat(output)
+		if err != nil {
+			return err
+		}
+		err = setVerbosity(cmd)
+		if err != nil {
+			return err
+		}
+		if !strings.HasPrefix(cmd.CommandPath(), logs.Cmd.CommandPath()) {
+			err = runlog.Start(os.Args[1:], credentialFlags())
+			if err != nil {
+				cfmt.Warning.Printf("Could not create the log of this run: %v\n", err)
+			}
+		}
+		return credentials.Load(cmd.Flags())
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		cmdhelp.PrintHelp(cmd)
 	},
 }
 
 func Execute() {
-	cobra.CheckErr(RootCmd.Execute())
+	err := RootCmd.Execute()
+	_ = runlog.Close()
+	cobra.CheckErr(err)
+}
+
+func setVerbosity(cmd *cobra.Command) error {
+	verbose, err := cmd.Flags().GetBool("verbose")
+	if err != nil {
+		return err
+	}
+	quiet, err := cmd.Flags().GetBool("quiet")
+	if err != nil {
+		return err
+	}
+	if verbose && quiet {
+		return errors.New("--verbose and --quiet cannot be used together")
+	}
+
+	if verbose {
+		runlog.SetLevel(runlog.Verbose)
+	} else if quiet {
+		runlog.SetLevel(runlog.Quiet)
+		cfmt.SetQuiet(true)
+		progress.SetQuiet(true)
+	}
+	return nil
+}
+
+// credentialFlags are the flags whose values are kept out of the run log
+func credentialFlags() []string {
+	var r []string
+	for _, input := range credentials.Inputs {
+		r = append(r, input.Flag)
+	}
+	return r
 }
 
 func init() {
-	RootCmd.AddCommand(configCmd.Cmd, install.Cmd)
+	credentials.AddFlags(RootCmd.PersistentFlags())
+	RootCmd.PersistentFlags().BoolP("verbose", "v", false, "Also print the external commands run and details such as download retries")
+	RootCmd.PersistentFlags().BoolP("quiet", "q", false, "Only print warnings, errors and questions")
+	RootCmd.PersistentFlags().StringP("output", "o", string(report.Text), "Output format. 'json' writes one event per step and a summary to stdout, everything else goes to stderr")
+
+	RootCmd.AddCommand(configCmd.Cmd, install.Cmd, doctor.Cmd, ue.Cmd, cache.Cmd, uninstall.Cmd, logs.Cmd)
 	if test.Cmd != nil {
 		RootCmd.AddCommand(test.Cmd)
 	}
@@ -0,0 +1,69 @@
+package ue
+
+import (
+	"context"
+	"fmt"
+	"github.com/satisfactorymodding/SMEI/lib/env/gh"
+	"github.com/satisfactorymodding/SMEI/lib/env/ue"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var releasesCmd = &cobra.Command{
+	Use:   "releases",
+	Short: "List the Unreal Engine releases that can be installed",
+	Long:  "List the Unreal Engine releases that can be installed. Pin one with 'smei config set ue-release-tag <tag>' or install one with 'smei install --ue-version <tag>'",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		setup()
+
+		ctx := context.Background()
+		client, err := gh.AuthedClient(ctx)
+		if err != nil {
+			log.Fatalf("Could not make a GitHub client: %v", err)
+		}
+		err = ue.EnsureGithubAccess(ctx, client)
+		if err != nil {
+			log.Fatalf("Could not access the Unreal Engine repository: %v", err)
+		}
+
+		releases, err := ue.Releases(ctx, client)
+		if err != nil {
+			log.Fatalf("Could not list the releases: %v", err)
+		}
+		latest, err := ue.LatestRelease(ctx, client)
+		if err != nil {
+			log.Fatalf("Could not get the latest release: %v", err)
+		}
+		cached, err := ue.CachedReleaseTag()
+		if err != nil {
+			log.Fatalf("Could not get the cached release: %v", err)
+		}
+		pinned := ue.ReleaseTag()
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TAG\tNAME\tPUBLISHED\tNOTES")
+		for _, release := range releases {
+			var notes []string
+			if release.GetTagName() == latest.GetTagName() {
+				notes = append(notes, "latest")
+			}
+			if release.GetTagName() == pinned {
+				notes = append(notes, "pinned")
+			}
+			if release.GetTagName() == cached {
+				notes = append(notes, "cached")
+			}
+			if release.GetPrerelease() {
+				notes = append(notes, "pre-release")
+			}
+			fmt.Fprintf(w, "%v\t%v\t%v\t%v\n", release.GetTagName(), release.GetName(),
+				release.GetPublishedAt().Format("2006-01-02"), strings.Join(notes, ", "))
+		}
+		w.Flush()
+	},
+}
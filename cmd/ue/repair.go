@@ -0,0 +1,51 @@
+package ue
+
+import (
+	"github.com/satisfactorymodding/SMEI/config"
+	"github.com/satisfactorymodding/SMEI/lib/cfmt"
+	"github.com/satisfactorymodding/SMEI/lib/elevate"
+	"github.com/satisfactorymodding/SMEI/lib/env/ue"
+	"github.com/satisfactorymodding/SMEI/lib/report"
+	"log"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	repairUninstallerCmd.Flags().BoolP("nonelevated", "e", false, "Choose whether to elevate the process or not. Entries of installs for all users require privileges")
+}
+
+var repairUninstallerCmd = &cobra.Command{
+	Use:   "repair-uninstaller",
+	Short: "Restore the uninstaller of an Unreal Engine install that SMEI installed alongside",
+	Long: "Restore the uninstaller of an Unreal Engine install that SMEI installed alongside. " +
+		"SMEI disables it while installing so that the other install is not removed, and restores it if the install fails",
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := config.Setup()
+		if err != nil {
+			log.Fatalf("Could not set up the config: %v", err)
+		}
+
+		nonElevated, _ := cmd.Flags().GetBool("nonelevated")
+		if !nonElevated {
+			elevate.EnsureElevatedFinal()
+		}
+
+		err = ue.RepairUninstaller()
+		if errors.Is(err, ue.ErrNothingToRepair) {
+			cfmt.Sequence.Println("Nothing to repair: " + err.Error())
+			return
+		}
+		if err != nil {
+			if hint := report.HintOf(err); hint != "" {
+				cfmt.Warning.Println(hint)
+			}
+			cfmt.Error.Printf("Could not restore the uninstaller: %v\n", err)
+			os.Exit(1)
+		}
+		cfmt.Sequence.Println("The uninstaller was restored")
+	},
+}
@@ -0,0 +1,34 @@
+package ue
+
+import (
+	"github.com/satisfactorymodding/SMEI/config"
+	"github.com/satisfactorymodding/SMEI/lib/cmdhelp"
+	"github.com/satisfactorymodding/SMEI/lib/credentials"
+	"log"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	Cmd.AddCommand(releasesCmd, repairUninstallerCmd)
+}
+
+var Cmd = &cobra.Command{
+	Use:   "ue",
+	Short: "Manage the Unreal Engine",
+	Run: func(cmd *cobra.Command, args []string) {
+		cmdhelp.PrintHelp(cmd)
+	},
+}
+
+func setup() {
+	err := config.Setup()
+	if err != nil {
+		log.Fatalf("Could not set up the config: %v", err)
+	}
+
+	err = credentials.EnsurePassword()
+	if err != nil {
+		log.Fatalf("Could not get a password: %v", err)
+	}
+}
@@ -0,0 +1,173 @@
+package uninstall
+
+import (
+	"fmt"
+	"github.com/satisfactorymodding/SMEI/config"
+	"github.com/satisfactorymodding/SMEI/lib/cache"
+	"github.com/satisfactorymodding/SMEI/lib/cfmt"
+	"github.com/satisfactorymodding/SMEI/lib/cmdhelp"
+	"github.com/satisfactorymodding/SMEI/lib/credentials"
+	"github.com/satisfactorymodding/SMEI/lib/elevate"
+	"github.com/satisfactorymodding/SMEI/lib/env/gh"
+	"github.com/satisfactorymodding/SMEI/lib/env/project"
+	"github.com/satisfactorymodding/SMEI/lib/env/ue"
+	"github.com/satisfactorymodding/SMEI/lib/env/vs"
+	"github.com/satisfactorymodding/SMEI/lib/report"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	flags := Cmd.Flags()
+
+	flags.Bool("ue", false, "Uninstall the Unreal Engine SMEI installed and restore the uninstaller of the install it replaced")
+	flags.Bool("vs", false, "Uninstall the Visual Studio instance SMEI installed")
+	flags.Bool("project", false, "Remove the projects SMEI cloned. Use --target to only remove one")
+	flags.StringP("target", "t", "", "The target of the project to remove, as passed to 'smei install'")
+	flags.Bool("caches", false, "Remove every cached file")
+	flags.Bool("credentials", false, "Remove the stored Audiokinetic and GitHub credentials")
+	flags.Bool("all", false, "Remove everything above")
+	flags.BoolP("yes", "y", false, "Do not ask for confirmation")
+	flags.BoolP("nonelevated", "e", false, "Choose whether to elevate the process or not. Uninstalling UE and VS requires privileges")
+}
+
+var Cmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove the components SMEI installed. Installs SMEI did not create are never touched",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := config.Setup()
+		if err != nil {
+			log.Fatalf("Could not set up the config: %v", err)
+		}
+		err = viper.BindPFlags(cmd.LocalNonPersistentFlags())
+		if err != nil {
+			log.Fatalf("Could not bind the CLI flags to the configuration system: %v", err)
+		}
+
+		all := viper.GetBool("all")
+		selected := func(component string) bool {
+			return all || viper.GetBool(component)
+		}
+		if !selected("ue") && !selected("vs") && !selected("project") && !selected("caches") && !selected("credentials") {
+			cmdhelp.PrintHelp(cmd)
+			os.Exit(1)
+		}
+
+		targets, err := projectTargets()
+		if err != nil {
+			log.Fatalf("Could not list the cloned projects: %v", err)
+		}
+
+		if (selected("ue") || selected("vs")) && !viper.GetBool("nonelevated") {
+			elevate.EnsureElevatedFinal()
+		}
+
+		if !viper.GetBool("yes") {
+			confirm(selected, targets)
+		}
+
+		run := report.Start()
+		if selected("project") {
+			if len(targets) == 0 {
+				cfmt.Warning.Println("SMEI did not clone any project")
+			}
+			for _, target := range targets {
+				target := target
+				_ = run.Step("project", func() error {
+					return project.Remove(target)
+				})
+			}
+		}
+		if selected("ue") {
+			_ = run.Step("ue", ue.Uninstall)
+		}
+		if selected("vs") {
+			_ = run.Step("vs", vs.Uninstall)
+		}
+		if selected("caches") {
+			_ = run.Step("caches", clearCaches)
+		}
+		if selected("credentials") {
+			_ = run.Step("credentials", removeCredentials)
+		}
+		run.Finish(nil)
+
+		if run.Failed() {
+			os.Exit(1)
+		}
+		cfmt.Sequence.Println("Uninstall complete")
+	},
+}
+
+// projectTargets returns the projects to remove: the one passed with --target, or every cloned one
+func projectTargets() ([]string, error) {
+	if target := viper.GetString("target"); target != "" {
+		return []string{target}, nil
+	}
+	return project.ClonedTargets()
+}
+
+func confirm(selected func(string) bool, targets []string) {
+	if credentials.IsNonInteractive() {
+		log.Fatalf("Refusing to uninstall without confirmation while running non-interactively. Add --yes to confirm")
+	}
+
+	var removed []string
+	if selected("project") {
+		for _, target := range targets {
+			removed = append(removed, fmt.Sprintf("the project in '%v'", target))
+		}
+	}
+	if selected("ue") {
+		removed = append(removed, "the Unreal Engine")
+	}
+	if selected("vs") {
+		removed = append(removed, "Visual Studio")
+	}
+	if selected("caches") {
+		removed = append(removed, "the cached files")
+	}
+	if selected("credentials") {
+		removed = append(removed, "the stored credentials")
+	}
+
+	cfmt.Request.Printf("This will remove %v. Continue? [y/N] ", strings.Join(removed, ", "))
+	var answer string
+	_, _ = fmt.Scanln(&answer)
+	if !strings.EqualFold(answer, "y") && !strings.EqualFold(answer, "yes") {
+		cfmt.Sequence.Println("Nothing was removed")
+		os.Exit(0)
+	}
+}
+
+func clearCaches() error {
+	entries, err := cache.Entries(cache.Components)
+	if err != nil {
+		return errors.Wrap(err, "could not list the caches")
+	}
+	for _, entry := range entries {
+		err = cache.Remove(entry)
+		if err != nil {
+			return errors.Wrapf(err, "could not remove '%v'", entry.Path)
+		}
+	}
+	return nil
+}
+
+func removeCredentials() error {
+	err := credentials.WwiseLogout()
+	if err != nil {
+		return errors.Wrap(err, "could not remove the Audiokinetic credentials")
+	}
+	err = gh.Logout()
+	if err != nil {
+		return errors.Wrap(err, "could not remove the GitHub token")
+	}
+	return nil
+}
@@ -37,6 +37,9 @@ const (
 	WwiseEmail_key              = "wwise-email"
 	WwisePassword_key           = "wwise-password"
 	PassCheck_key               = "pass-check"
+	SecretStore_key             = "secret-store"
+	DownloadConcurrency_key     = "download-concurrency"
+	UEReleaseTag_key            = "ue-release-tag"
 )
 
 func init() {
@@ -44,7 +47,17 @@ func init() {
 	setupCacheDir()
 }
 
+// Setup reads the config, creating it with the defaults if there is none
 func Setup() error {
+	return setup(true)
+}
+
+// SetupReadOnly reads the config if there is one, for runs that must not change anything such as dry runs
+func SetupReadOnly() error {
+	return setup(false)
+}
+
+func setup(create bool) error {
 	viper.SetEnvPrefix("SMEI")
 	viper.AutomaticEnv()
 
@@ -55,12 +68,12 @@ func Setup() error {
 	viper.AddConfigPath(ConfigDir)
 	err := viper.ReadInConfig()
 	_, notFound := err.(viper.ConfigFileNotFoundError)
-	if notFound {
+	if notFound && create {
 		err = os.MkdirAll(ConfigDir, 0744)
 		if err != nil {
 			return errors.Wrap(err, "could not create config directory")
 		}
-		err = viper.SafeWriteConfig()
+		err = Write()
 		if err != nil {
 			return errors.Wrap(err, "could not write the default config")
 		}
@@ -88,15 +101,26 @@ func setupCacheDir() {
 }
 
 func setupDefaults() {
-	viper.SetDefault(GHClientID_key, "0e4260b720ae65240864")
-	viper.SetDefault(UEInstallPath_key, filepath.Join(os.ExpandEnv("$ProgramFiles"), UEFolderName))
-	viper.SetDefault(UESkipReinstall_key, false)
-	viper.SetDefault(PreserveUEInstaller_key, true)
-	viper.SetDefault(DeveloperMode_key, false)
-	viper.SetDefault(VSInstallPath_key, filepath.Join(os.ExpandEnv("$ProgramFiles"), "Microsoft Visual Studio", "2022", "Community"))
-	viper.SetDefault(WwiseCacheDir_key, filepath.Join(CacheDir, "Wwise"))
-	viper.SetDefault(WwiseSdkVersion_key, "2021.1.8.7831")
-	viper.SetDefault(WwiseIntegrationVersion_key, "2021.1.8.2285")
+	for key, value := range Defaults() {
+		viper.SetDefault(key, value)
+	}
+}
+
+func Defaults() map[string]interface{} {
+	return map[string]interface{}{
+		GHClientID_key:              "0e4260b720ae65240864",
+		UEInstallPath_key:           filepath.Join(os.ExpandEnv("$ProgramFiles"), UEFolderName),
+		UESkipReinstall_key:         false,
+		PreserveUEInstaller_key:     true,
+		DeveloperMode_key:           false,
+		VSInstallPath_key:           filepath.Join(os.ExpandEnv("$ProgramFiles"), "Microsoft Visual Studio", "2022", "Community"),
+		WwiseCacheDir_key:           filepath.Join(CacheDir, "Wwise"),
+		WwiseSdkVersion_key:         "2021.1.8.7831",
+		WwiseIntegrationVersion_key: "2021.1.8.2285",
+		SecretStore_key:             EncryptedStore,
+		DownloadConcurrency_key:     4,
+		UEReleaseTag_key:            "",
+	}
 }
 
 func SetPassword(newPassword secret.String) error {
@@ -111,10 +135,14 @@ func SetPassword(newPassword secret.String) error {
 		}
 
 		viper.Set(PassCheck_key, encrypted)
-		err = viper.WriteConfig()
+		err = Write()
 	}
 
-	decrypted, err := crypt.Decrypt(string(newPassword), viper.GetString(PassCheck_key))
+	storedPassCheck := viper.GetString(PassCheck_key)
+	decrypted, err := crypt.Decrypt(string(newPassword), storedPassCheck)
+	if err == crypt.ErrDecryption {
+		return InvalidPasswordError{}
+	}
 	if err != nil {
 		return errors.Wrap(err, "could not decrypt the pass check")
 	}
@@ -123,7 +151,14 @@ func SetPassword(newPassword secret.String) error {
 		return InvalidPasswordError{}
 	}
 
-	err = viper.WriteConfig()
+	if crypt.IsLegacy(storedPassCheck) {
+		err = migrate(string(newPassword), PassCheck_key, decrypted)
+		if err != nil {
+			return errors.Wrap(err, "could not migrate the pass check")
+		}
+	}
+
+	err = Write()
 
 	if err != nil {
 		return errors.Wrap(err, "could not persist the config changes")
@@ -158,43 +193,74 @@ func (e InvalidPasswordError) Error() string {
 	return "invalid password"
 }
 
-func SetSecretString(key string, str secret.String) error {
-	if !HasPassword() {
-		return MissingPasswordError{}
-	}
+var MissingPassword = MissingPasswordError{}
+
+type MissingPasswordError struct{}
 
-	encrypted, err := crypt.Encrypt(string(password), string(str))
+func (e MissingPasswordError) Error() string {
+	return "missing password"
+}
+
+// migrate encrypts a value that was read in the legacy format again with the current one
+func migrate(key, configKey, decrypted string) error {
+	encrypted, err := crypt.Encrypt(key, decrypted)
 	if err != nil {
 		return errors.Wrap(err, "could not encrypt")
 	}
-
-	viper.Set(key, encrypted)
-
+	viper.Set(configKey, encrypted)
 	return nil
 }
 
-var MissingPassword = MissingPasswordError{}
+// ChangePassword encrypts every secret and the pass check again with newPassword, after verifying currentPassword.
+// The config file is replaced atomically so an interrupted change never leaves a mix of keys
+func ChangePassword(currentPassword, newPassword secret.String) error {
+	if len(newPassword) < MinPasswordLength {
+		return PasswordTooShortError{}
+	}
 
-type MissingPasswordError struct{}
+	decrypted, err := crypt.Decrypt(string(currentPassword), viper.GetString(PassCheck_key))
+	if err == crypt.ErrDecryption || (err == nil && decrypted != passCheck) {
+		return InvalidPasswordError{}
+	}
+	if err != nil {
+		return errors.Wrap(err, "could not decrypt the pass check")
+	}
 
-func (e MissingPasswordError) Error() string {
-	return "missing password"
-}
+	reencrypted := map[string]string{}
+	reencrypted[PassCheck_key], err = crypt.Encrypt(string(newPassword), passCheck)
+	if err != nil {
+		return errors.Wrap(err, "could not encrypt the pass check")
+	}
 
-func GetSecretString(key string) (secret.String, error) {
-	if !HasPassword() {
-		return "", MissingPasswordError{}
+	for _, key := range SecretKeys {
+		value := viper.GetString(key)
+		if value == "" {
+			continue
+		}
+		decrypted, err := crypt.Decrypt(string(currentPassword), value)
+		if err != nil {
+			return errors.Wrapf(err, "could not decrypt '%v'", key)
+		}
+		reencrypted[key], err = crypt.Encrypt(string(newPassword), decrypted)
+		if err != nil {
+			return errors.Wrapf(err, "could not encrypt '%v'", key)
+		}
 	}
 
-	str := viper.GetString(key)
-	if str == "" {
-		return "", nil
+	previous := map[string]interface{}{}
+	for key, value := range reencrypted {
+		previous[key] = viper.Get(key)
+		viper.Set(key, value)
 	}
 
-	decrypted, err := crypt.Decrypt(string(password), str)
+	err = Write()
 	if err != nil {
-		return "", errors.Wrap(err, "could not decrypt")
+		for key, value := range previous {
+			viper.Set(key, value)
+		}
+		return errors.Wrap(err, "could not persist the config changes")
 	}
 
-	return secret.String(decrypted), nil
+	password = newPassword
+	return nil
 }
@@ -0,0 +1,255 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+type KeyType string
+
+const (
+	StringType  KeyType = "string"
+	BoolType    KeyType = "bool"
+	IntType     KeyType = "int"
+	PathType    KeyType = "path"
+	VersionType KeyType = "version"
+	ChoiceType  KeyType = "choice"
+)
+
+// Key describes a config key for the config commands
+type Key struct {
+	Name        string
+	Type        KeyType
+	Description string
+	// Secret keys are stored through the SecretStore
+	Secret bool
+	// Internal keys are managed by SMEI and cannot be set by users
+	Internal bool
+	// Choices are the accepted values of ChoiceType keys
+	Choices []string
+}
+
+// Keys lists every known config key. New keys must be added here
+var Keys = []Key{
+	{Name: GHClientID_key, Type: StringType, Description: "GitHub OAuth app client ID used for the device flow"},
+	{Name: GHToken_key, Type: StringType, Secret: true, Description: "GitHub access token"},
+	{Name: UEInstallPath_key, Type: PathType, Description: "Where the Unreal Engine is installed"},
+	{Name: UESkipReinstall_key, Type: BoolType, Description: "Do not run the UE installer if the engine is already installed"},
+	{Name: PreserveUEInstaller_key, Type: BoolType, Description: "Keep the downloaded UE installer"},
+	{Name: DeveloperMode_key, Type: BoolType, Description: "Use a default SMEI password, for SMEI development"},
+	{Name: VSInstallPath_key, Type: PathType, Description: "Where Visual Studio is installed"},
+	{Name: VSSkipReinstall_key, Type: BoolType, Description: "Do not run the Visual Studio installer"},
+	{Name: WwiseCacheDir_key, Type: PathType, Description: "Where Wwise files are downloaded"},
+	{Name: WwiseSdkVersion_key, Type: VersionType, Description: "Wwise SDK version"},
+	{Name: WwiseIntegrationVersion_key, Type: VersionType, Description: "Wwise Unreal integration version"},
+	{Name: WwiseEmail_key, Type: StringType, Secret: true, Description: "Audiokinetic account email"},
+	{Name: WwisePassword_key, Type: StringType, Secret: true, Description: "Audiokinetic account password"},
+	{Name: PassCheck_key, Type: StringType, Internal: true, Description: "Used to verify the SMEI password"},
+	{Name: SecretStore_key, Type: ChoiceType, Choices: []string{EncryptedStore, KeyringStore}, Description: "Where secrets are stored"},
+	{Name: DownloadConcurrency_key, Type: IntType, Description: "How many files are downloaded at once"},
+	{Name: UEReleaseTag_key, Type: StringType, Description: "Unreal Engine release to install. Empty for the latest release"},
+}
+
+// SecretKeys are the keys stored through the SecretStore
+var SecretKeys = secretKeys()
+
+func secretKeys() []string {
+	var r []string
+	for _, key := range Keys {
+		if key.Secret {
+			r = append(r, key.Name)
+		}
+	}
+	return r
+}
+
+func FindKey(name string) (Key, bool) {
+	for _, key := range Keys {
+		if key.Name == name {
+			return key, true
+		}
+	}
+	return Key{}, false
+}
+
+var versionRegex = regexp.MustCompile(`^\d+(\.\d+)*$`)
+
+// Parse validates value against the key's type and converts it
+func (k Key) Parse(value string) (interface{}, error) {
+	switch k.Type {
+	case BoolType:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, errors.Errorf("'%v' is not a boolean", value)
+		}
+		return b, nil
+	case IntType:
+		i, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, errors.Errorf("'%v' is not an integer", value)
+		}
+		return i, nil
+	case PathType:
+		if strings.TrimSpace(value) == "" {
+			return nil, errors.New("the path cannot be empty")
+		}
+		return filepath.Clean(value), nil
+	case VersionType:
+		if !versionRegex.MatchString(value) {
+			return nil, errors.Errorf("'%v' is not a version, expected something like 2021.1.8.7831", value)
+		}
+		return value, nil
+	case ChoiceType:
+		for _, choice := range k.Choices {
+			if choice == value {
+				return value, nil
+			}
+		}
+		return nil, errors.Errorf("'%v' is not one of %v", value, k.Choices)
+	}
+	return value, nil
+}
+
+type Source string
+
+const (
+	SourceEnv     Source = "env"
+	SourceFile    Source = "config file"
+	SourceDefault Source = "default"
+	SourceUnset   Source = "unset"
+)
+
+// EnvName is the environment variable that overrides the key
+func (k Key) EnvName() string {
+	return "SMEI_" + strings.ToUpper(k.Name)
+}
+
+// Source reports where the current value of the key comes from
+func (k Key) Source() Source {
+	if _, ok := os.LookupEnv(k.EnvName()); ok {
+		return SourceEnv
+	}
+	if viper.InConfig(k.Name) {
+		return SourceFile
+	}
+	if _, ok := Defaults()[k.Name]; ok {
+		return SourceDefault
+	}
+	return SourceUnset
+}
+
+// Unset removes key from the config file, so its default applies again
+func Unset(key string) error {
+	configFile := configFilePath()
+	values, err := readConfigFile(configFile)
+	if err != nil {
+		return err
+	}
+	if _, ok := values[key]; !ok {
+		return nil
+	}
+	delete(values, key)
+
+	err = writeConfigFile(configFile, values)
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(viper.ReadInConfig(), "could not read the config again")
+}
+
+// Write persists the known keys to the config file atomically. Keys that are not in the file yet are only written if
+// their value differs from the default, so keys left unset keep following their default. Environment overrides are never written
+func Write() error {
+	configFile := configFilePath()
+	values, err := readConfigFile(configFile)
+	if err != nil {
+		return err
+	}
+
+	defaults := Defaults()
+	for _, key := range Keys {
+		if _, ok := os.LookupEnv(key.EnvName()); ok {
+			continue
+		}
+		value := viper.Get(key.Name)
+		if _, ok := values[key.Name]; !ok && isDefault(value, defaults[key.Name]) {
+			continue
+		}
+		values[key.Name] = value
+	}
+
+	err = os.MkdirAll(filepath.Dir(configFile), 0744)
+	if err != nil {
+		return errors.Wrap(err, "could not create config directory")
+	}
+	err = writeConfigFile(configFile, values)
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(viper.ReadInConfig(), "could not read the config again")
+}
+
+// isDefault reports whether value is the default, or is empty for keys without one
+func isDefault(value, defaultValue interface{}) bool {
+	if defaultValue == nil {
+		return value == nil || value == ""
+	}
+	return fmt.Sprint(value) == fmt.Sprint(defaultValue)
+}
+
+func configFilePath() string {
+	configFile := viper.ConfigFileUsed()
+	if configFile == "" {
+		configFile = filepath.Join(ConfigDir, "config.yaml")
+	}
+	return configFile
+}
+
+// readConfigFile returns the values of the config file as-is, without defaults nor overrides. A missing file is empty
+func readConfigFile(configFile string) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	data, err := os.ReadFile(configFile)
+	if os.IsNotExist(err) {
+		return values, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read the config")
+	}
+
+	err = yaml.Unmarshal(data, &values)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse the config")
+	}
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+	return values, nil
+}
+
+// writeConfigFile writes values to a temporary file then replaces the config file with it
+func writeConfigFile(configFile string, values map[string]interface{}) error {
+	data, err := yaml.Marshal(values)
+	if err != nil {
+		return errors.Wrap(err, "could not marshal the config")
+	}
+
+	tmp := configFile + ".tmp"
+	err = os.WriteFile(tmp, data, 0644)
+	if err != nil {
+		return errors.Wrap(err, "could not write the temporary config")
+	}
+	err = os.Rename(tmp, configFile)
+	if err != nil {
+		_ = os.Remove(tmp)
+		return errors.Wrap(err, "could not replace the config")
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func fileValues(t *testing.T) map[string]interface{} {
+	t.Helper()
+	values, err := readConfigFile(viper.ConfigFileUsed())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return values
+}
+
+func TestWriteSkipsDefaults(t *testing.T) {
+	useTempConfig(t)
+	setupDefaults()
+	key, _ := FindKey(DownloadConcurrency_key)
+
+	err := Write()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values := fileValues(t); len(values) != 0 {
+		t.Errorf("config file = %v, want no defaults", values)
+	}
+	if key.Source() != SourceDefault {
+		t.Errorf("Source() = %v, want %v", key.Source(), SourceDefault)
+	}
+
+	viper.Set(key.Name, 8)
+	err = Write()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value := fileValues(t)[key.Name]; value != 8 {
+		t.Errorf("config file value = %v, want 8", value)
+	}
+	if key.Source() != SourceFile {
+		t.Errorf("Source() = %v after setting, want %v", key.Source(), SourceFile)
+	}
+
+	err = Unset(key.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Unsetting does not remove the value set earlier in this process, which must not be written back
+	viper.Set(key.Name, Defaults()[key.Name])
+	err = Write()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := fileValues(t)[key.Name]; ok {
+		t.Errorf("unset key written back to the config file")
+	}
+	if key.Source() != SourceDefault {
+		t.Errorf("Source() = %v after unsetting, want %v", key.Source(), SourceDefault)
+	}
+}
+
+func TestWriteKeepsFileValues(t *testing.T) {
+	useTempConfig(t)
+	setupDefaults()
+
+	// A value in the file is kept even if it is the default, so it does not change with the default
+	viper.Set(WwiseSdkVersion_key, Defaults()[WwiseSdkVersion_key])
+	err := writeConfigFile(viper.ConfigFileUsed(), map[string]interface{}{WwiseSdkVersion_key: Defaults()[WwiseSdkVersion_key]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = Write()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value := fileValues(t)[WwiseSdkVersion_key]; value != Defaults()[WwiseSdkVersion_key] {
+		t.Errorf("config file value = %v, want the default kept", value)
+	}
+}
+
+func TestWriteSkipsEnv(t *testing.T) {
+	useTempConfig(t)
+	setupDefaults()
+	viper.SetEnvPrefix("SMEI")
+	viper.AutomaticEnv()
+	key, _ := FindKey(UEReleaseTag_key)
+	t.Setenv(key.EnvName(), "v5.0.3-css-40")
+
+	err := Write()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := fileValues(t)[key.Name]; ok {
+		t.Errorf("environment override written to the config file")
+	}
+	if key.Source() != SourceEnv {
+		t.Errorf("Source() = %v, want %v", key.Source(), SourceEnv)
+	}
+}
@@ -0,0 +1,175 @@
+package config
+
+import (
+	"github.com/satisfactorymodding/SMEI/lib/crypt"
+	"github.com/satisfactorymodding/SMEI/lib/keyring"
+	"github.com/satisfactorymodding/SMEI/lib/secret"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+// SecretStore persists the secret keys
+type SecretStore interface {
+	Get(key string) (secret.String, error)
+	Set(key string, value secret.String) error
+	Delete(key string) error
+	// NeedsPassword reports whether the SMEI password must be set before using the store
+	NeedsPassword() bool
+}
+
+// Values of SecretStore_key
+const (
+	EncryptedStore = "encrypted"
+	KeyringStore   = "keyring"
+)
+
+const keyringService = "SMEI"
+
+var secretStoreOverride SecretStore
+
+// Secrets returns the store selected by SecretStore_key, unless one was set with SetSecretStore
+func Secrets() SecretStore {
+	if secretStoreOverride != nil {
+		return secretStoreOverride
+	}
+	if viper.GetString(SecretStore_key) == KeyringStore {
+		return keyringSecretStore{}
+	}
+	return encryptedSecretStore{}
+}
+
+// SetSecretStore overrides the configured store. Passing nil goes back to the configured one
+func SetSecretStore(store SecretStore) {
+	secretStoreOverride = store
+}
+
+func SetSecretString(key string, str secret.String) error {
+	return Secrets().Set(key, str)
+}
+
+func GetSecretString(key string) (secret.String, error) {
+	return Secrets().Get(key)
+}
+
+func DeleteSecret(key string) error {
+	return Secrets().Delete(key)
+}
+
+// encryptedSecretStore encrypts the secrets with the SMEI password and keeps them in the config.
+// Set and Delete do not persist the config, Write must be called afterwards
+type encryptedSecretStore struct{}
+
+func (encryptedSecretStore) Get(key string) (secret.String, error) {
+	if !HasPassword() {
+		return "", MissingPasswordError{}
+	}
+
+	str := viper.GetString(key)
+	if str == "" {
+		return "", nil
+	}
+
+	decrypted, err := crypt.Decrypt(string(password), str)
+	if err != nil {
+		return "", errors.Wrap(err, "could not decrypt")
+	}
+
+	if crypt.IsLegacy(str) {
+		err = migrate(string(password), key, decrypted)
+		if err != nil {
+			return "", errors.Wrapf(err, "could not migrate '%v'", key)
+		}
+		err = Write()
+		if err != nil {
+			return "", errors.Wrap(err, "could not persist the config changes")
+		}
+	}
+
+	return secret.String(decrypted), nil
+}
+
+func (encryptedSecretStore) Set(key string, str secret.String) error {
+	if !HasPassword() {
+		return MissingPasswordError{}
+	}
+
+	encrypted, err := crypt.Encrypt(string(password), string(str))
+	if err != nil {
+		return errors.Wrap(err, "could not encrypt")
+	}
+
+	viper.Set(key, encrypted)
+
+	return nil
+}
+
+func (encryptedSecretStore) Delete(key string) error {
+	viper.Set(key, "")
+	return nil
+}
+
+func (encryptedSecretStore) NeedsPassword() bool {
+	return true
+}
+
+// keyringSecretStore keeps the secrets in the OS credential store
+type keyringSecretStore struct{}
+
+func (keyringSecretStore) Get(key string) (secret.String, error) {
+	value, err := keyring.Get(keyringService, key)
+	if err == keyring.ErrNotFound {
+		return "", nil
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "could not read from the keyring")
+	}
+	return secret.String(value), nil
+}
+
+func (keyringSecretStore) Set(key string, value secret.String) error {
+	return errors.Wrap(keyring.Set(keyringService, key, string(value)), "could not write to the keyring")
+}
+
+func (keyringSecretStore) Delete(key string) error {
+	return errors.Wrap(keyring.Delete(keyringService, key), "could not delete from the keyring")
+}
+
+func (keyringSecretStore) NeedsPassword() bool {
+	return false
+}
+
+// MemoryStore keeps secrets in memory. Meant to be used with SetSecretStore in tests
+type MemoryStore struct {
+	lock    sync.Mutex
+	secrets map[string]secret.String
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{secrets: map[string]secret.String{}}
+}
+
+func (s *MemoryStore) Get(key string) (secret.String, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.secrets[key], nil
+}
+
+func (s *MemoryStore) Set(key string, value secret.String) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.secrets[key] = value
+	return nil
+}
+
+func (s *MemoryStore) Delete(key string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.secrets, key)
+	return nil
+}
+
+func (s *MemoryStore) NeedsPassword() bool {
+	return false
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"github.com/satisfactorymodding/SMEI/lib/secret"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+func useTempConfig(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(configFile, nil, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	viper.SetConfigFile(configFile)
+	t.Cleanup(func() {
+		viper.Reset()
+		password = ""
+		SetSecretStore(nil)
+	})
+}
+
+func TestSecretsSelection(t *testing.T) {
+	useTempConfig(t)
+
+	if _, ok := Secrets().(encryptedSecretStore); !ok {
+		t.Errorf("default store is %T, want the encrypted store", Secrets())
+	}
+
+	viper.Set(SecretStore_key, KeyringStore)
+	if _, ok := Secrets().(keyringSecretStore); !ok {
+		t.Errorf("store is %T with %v set to %v, want the keyring store", Secrets(), SecretStore_key, KeyringStore)
+	}
+
+	memory := NewMemoryStore()
+	SetSecretStore(memory)
+	if Secrets() != memory {
+		t.Errorf("store is %T, want the override", Secrets())
+	}
+
+	SetSecretStore(nil)
+	if _, ok := Secrets().(keyringSecretStore); !ok {
+		t.Errorf("store is %T after removing the override, want the keyring store", Secrets())
+	}
+}
+
+func TestEncryptedStore(t *testing.T) {
+	useTempConfig(t)
+	store := encryptedSecretStore{}
+
+	_, err := store.Get(GHToken_key)
+	if !errors.As(err, &MissingPasswordError{}) {
+		t.Fatalf("Get() without a password error = %v, want MissingPasswordError", err)
+	}
+
+	err = SetPassword("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testRoundTrip(t, store)
+
+	err = store.Set(GHToken_key, "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored := viper.GetString(GHToken_key); stored == "" || stored == "token" {
+		t.Errorf("config value = %q, want the encrypted token", stored)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testRoundTrip(t, NewMemoryStore())
+}
+
+// testRoundTrip checks that store returns what was set, and nothing once it is deleted
+func testRoundTrip(t *testing.T, store SecretStore) {
+	t.Helper()
+	for _, value := range []secret.String{"first", "second"} {
+		err := store.Set(WwisePassword_key, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := store.Get(WwisePassword_key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != value {
+			t.Errorf("Get() = %q, want %q", string(got), string(value))
+		}
+	}
+
+	err := store.Delete(WwisePassword_key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := store.Get(WwisePassword_key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "" {
+		t.Errorf("Get() after Delete() = %q, want nothing", string(got))
+	}
+}
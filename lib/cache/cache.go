@@ -0,0 +1,143 @@
+package cache
+
+import (
+	"github.com/satisfactorymodding/SMEI/config"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+const (
+	UE    = "ue"
+	VS    = "vs"
+	Wwise = "wwise"
+)
+
+// Component is a kind of cached files
+type Component struct {
+	Name        string
+	Description string
+	// Dir returns where the files are cached. A function as it can depend on the config
+	Dir func() string
+	// Depth is how deep the entries are in Dir. 0 makes the whole directory a single entry
+	Depth int
+}
+
+// Components lists every cache. Every cached file must be in one of them
+var Components = []Component{
+	{
+		Name:        UE,
+		Description: "Unreal Engine installer",
+		Dir:         func() string { return filepath.Join(config.CacheDir, "UE-Installer") },
+	},
+	{
+		Name:        VS,
+		Description: "Visual Studio installer",
+		Dir:         func() string { return filepath.Join(config.CacheDir, "VS-Installer") },
+		Depth:       1,
+	},
+	{
+		Name:        Wwise,
+		Description: "Wwise SDK and Unreal integration files, one entry per version",
+		Dir:         func() string { return viper.GetString(config.WwiseCacheDir_key) },
+		Depth:       2,
+	},
+}
+
+func Find(name string) (Component, bool) {
+	for _, component := range Components {
+		if component.Name == name {
+			return component, true
+		}
+	}
+	return Component{}, false
+}
+
+// Dir returns where the files of a component are cached
+func Dir(name string) string {
+	component, ok := Find(name)
+	if !ok {
+		panic("unknown cache component " + name)
+	}
+	return component.Dir()
+}
+
+// Entry is a cached file or directory that can be removed on its own
+type Entry struct {
+	Component string
+	Path      string
+	Size      int64
+	// Modified is the last time anything in the entry was modified
+	Modified time.Time
+}
+
+// Entries lists the entries of the components. Missing caches have no entries
+func Entries(components []Component) ([]Entry, error) {
+	var r []Entry
+	for _, component := range components {
+		entries, err := entries(component.Name, component.Dir(), component.Depth)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not list the %v cache", component.Name)
+		}
+		r = append(r, entries...)
+	}
+	return r, nil
+}
+
+func entries(component, path string, depth int) ([]Entry, error) {
+	stat, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if depth == 0 || !stat.IsDir() {
+		size, modified, err := measure(path)
+		if err != nil {
+			return nil, err
+		}
+		return []Entry{{Component: component, Path: path, Size: size, Modified: modified}}, nil
+	}
+
+	children, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	var r []Entry
+	for _, child := range children {
+		childEntries, err := entries(component, filepath.Join(path, child.Name()), depth-1)
+		if err != nil {
+			return nil, err
+		}
+		r = append(r, childEntries...)
+	}
+	return r, nil
+}
+
+// measure returns the total size of path and the last time anything in it was modified
+func measure(path string) (int64, time.Time, error) {
+	var size int64
+	var modified time.Time
+	err := filepath.Walk(path, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			size += info.Size()
+		}
+		if info.ModTime().After(modified) {
+			modified = info.ModTime()
+		}
+		return nil
+	})
+	return size, modified, err
+}
+
+func Remove(entry Entry) error {
+	return os.RemoveAll(entry.Path)
+}
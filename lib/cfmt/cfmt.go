@@ -1,13 +1,69 @@
 package cfmt
 
 import (
+	"fmt"
+	"io"
+	"sync"
+
 	"github.com/fatih/color"
 )
 
-var Warning = color.New(color.FgYellow, color.Bold)
+var Warning = &Printer{Color: color.New(color.FgYellow, color.Bold)}
 
-var Error = color.New(color.FgRed, color.Bold)
+var Error = &Printer{Color: color.New(color.FgRed, color.Bold)}
 
-var Sequence = color.New(color.FgCyan, color.Italic)
+// Sequence describes what a command is doing. Hidden with --quiet
+var Sequence = &Printer{Color: color.New(color.FgCyan, color.Italic), quietable: true}
 
 var Request = color.New(color.FgWhite, color.Bold)
+
+var lock sync.Mutex
+var quiet bool
+var log io.Writer
+
+// SetQuiet hides the Sequence messages
+func SetQuiet(q bool) {
+	lock.Lock()
+	defer lock.Unlock()
+	quiet = q
+}
+
+// SetLog makes every message also be written to w, without colors. Even hidden ones are written
+func SetLog(w io.Writer) {
+	lock.Lock()
+	defer lock.Unlock()
+	log = w
+}
+
+// Printer prints in color, and copies what it prints to the log
+type Printer struct {
+	*color.Color
+	quietable bool
+}
+
+func (p *Printer) Print(a ...interface{}) (int, error) {
+	return p.print(fmt.Sprint(a...))
+}
+
+func (p *Printer) Printf(format string, a ...interface{}) (int, error) {
+	return p.print(fmt.Sprintf(format, a...))
+}
+
+func (p *Printer) Println(a ...interface{}) (int, error) {
+	return p.print(fmt.Sprintln(a...))
+}
+
+func (p *Printer) print(s string) (int, error) {
+	lock.Lock()
+	hidden := p.quietable && quiet
+	w := log
+	lock.Unlock()
+
+	if w != nil {
+		_, _ = io.WriteString(w, s)
+	}
+	if hidden {
+		return len(s), nil
+	}
+	return p.Color.Print(s)
+}
@@ -0,0 +1,169 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Command is an external program to run
+type Command struct {
+	Name string
+	Args []string
+	Dir  string
+	// Env overrides variables of the current environment
+	Env    map[string]string
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+	// SuccessCodes are the non-zero exit codes that still mean success, such as 3010 when a reboot is required
+	SuccessCodes []int
+	// Secrets are values passed in the arguments that are replaced with <secret> wherever the command is printed or logged
+	Secrets []string
+}
+
+func (c Command) String() string {
+	parts := []string{quote(c.Name)}
+	for _, arg := range c.Args {
+		parts = append(parts, quote(arg))
+	}
+	r := strings.Join(parts, " ")
+	for _, secret := range c.Secrets {
+		if secret != "" {
+			r = strings.ReplaceAll(r, secret, "<secret>")
+		}
+	}
+	return r
+}
+
+func quote(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t\"") {
+		return fmt.Sprintf("%q", s)
+	}
+	return s
+}
+
+type Result struct {
+	ExitCode int
+}
+
+// ExitError is returned when a command exits with a code that is not a success
+type ExitError struct {
+	Command  string
+	ExitCode int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("'%v' exited with code %v", e.Command, e.ExitCode)
+}
+
+// ExitCode returns the exit code carried by err, -1 if there is none
+func ExitCode(err error) int {
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode
+	}
+	return -1
+}
+
+// Runner runs commands. Cancelling ctx kills the command
+type Runner interface {
+	Run(ctx context.Context, cmd Command) (Result, error)
+}
+
+var lock sync.Mutex
+var runner Runner = &ExecRunner{}
+
+// Use replaces the runner every command goes through, such as with a Fake. Returns the previous one
+func Use(r Runner) Runner {
+	lock.Lock()
+	defer lock.Unlock()
+	previous := runner
+	runner = r
+	return previous
+}
+
+// Run runs cmd with the current runner
+func Run(ctx context.Context, cmd Command) (Result, error) {
+	lock.Lock()
+	r := runner
+	lock.Unlock()
+	return r.Run(ctx, cmd)
+}
+
+// result maps an exit code to the result of cmd
+func result(cmd Command, code int) (Result, error) {
+	r := Result{ExitCode: code}
+	if code == 0 {
+		return r, nil
+	}
+	for _, success := range cmd.SuccessCodes {
+		if code == success {
+			return r, nil
+		}
+	}
+	return r, &ExitError{Command: cmd.String(), ExitCode: code}
+}
+
+// ExecRunner runs commands as processes. Their command line, output and exit code are also written to Log if it is set
+type ExecRunner struct {
+	Log io.Writer
+	// Trace receives the command lines and exit codes only, if set
+	Trace io.Writer
+}
+
+func (r *ExecRunner) Run(ctx context.Context, cmd Command) (Result, error) {
+	process := exec.CommandContext(ctx, cmd.Name, cmd.Args...)
+	process.Dir = cmd.Dir
+	process.Stdin = cmd.Stdin
+	process.Stdout = r.tee(cmd.Stdout)
+	process.Stderr = r.tee(cmd.Stderr)
+	if len(cmd.Env) > 0 {
+		process.Env = os.Environ()
+		for name, value := range cmd.Env {
+			process.Env = append(process.Env, name+"="+value)
+		}
+	}
+
+	r.logf("> %v\n", cmd)
+	err := process.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && ctx.Err() == nil {
+		r.logf("< exit code %v\n", exitErr.ExitCode())
+		return result(cmd, exitErr.ExitCode())
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		r.logf("< failed: %v\n", err)
+		return Result{ExitCode: -1}, errors.Wrapf(err, "could not run '%v'", cmd.Name)
+	}
+	r.logf("< exit code 0\n")
+	return Result{}, nil
+}
+
+func (r *ExecRunner) tee(w io.Writer) io.Writer {
+	switch {
+	case r.Log == nil:
+		return w
+	case w == nil:
+		return r.Log
+	default:
+		return io.MultiWriter(w, r.Log)
+	}
+}
+
+func (r *ExecRunner) logf(format string, args ...interface{}) {
+	for _, w := range []io.Writer{r.Log, r.Trace} {
+		if w != nil {
+			fmt.Fprintf(w, format, args...)
+		}
+	}
+}
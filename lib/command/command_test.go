@@ -0,0 +1,44 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestStringRedactsSecrets(t *testing.T) {
+	cmd := Command{
+		Name:    "powershell",
+		Args:    []string{"-Command", "Start-Process -ArgumentList 'install --password hunter22 --github-token=ghp_abc'"},
+		Secrets: []string{"hunter22", "ghp_abc", ""},
+	}
+	s := cmd.String()
+	if strings.Contains(s, "hunter22") || strings.Contains(s, "ghp_abc") {
+		t.Fatalf("secrets were not redacted: %v", s)
+	}
+	if !strings.Contains(s, "--password <secret>") || !strings.Contains(s, "--github-token=<secret>") {
+		t.Fatalf("unexpected command line: %v", s)
+	}
+}
+
+func TestExecRunnerDoesNotLogSecrets(t *testing.T) {
+	var log, trace bytes.Buffer
+	runner := &ExecRunner{Log: &log, Trace: &trace}
+	_, err := runner.Run(context.Background(), Command{
+		Name:    "smei-missing-program",
+		Args:    []string{"--password=hunter22"},
+		Secrets: []string{"hunter22"},
+	})
+	if err == nil {
+		t.Fatal("a missing program was run")
+	}
+	for name, out := range map[string]string{"log": log.String(), "trace": trace.String()} {
+		if strings.Contains(out, "hunter22") {
+			t.Errorf("the %v contains the secret: %v", name, out)
+		}
+		if !strings.Contains(out, "--password=<secret>") {
+			t.Errorf("the %v does not contain the command line: %v", name, out)
+		}
+	}
+}
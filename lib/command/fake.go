@@ -0,0 +1,95 @@
+package command
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Reply is the scripted outcome of a command run by a Fake
+type Reply struct {
+	ExitCode int
+	Stdout   string
+	Stderr   string
+	// Err is returned instead of running the command, as when the program does not exist
+	Err error
+}
+
+// Fake records the commands it is given and answers with scripted replies instead of running them
+type Fake struct {
+	lock    sync.Mutex
+	calls   []Command
+	replies map[string][]Reply
+}
+
+func NewFake() *Fake {
+	return &Fake{replies: map[string][]Reply{}}
+}
+
+// Script queues replies for the commands named name, matched on the file name without extension, case-insensitively.
+// The last reply is repeated once the others are used. Unscripted commands exit with 0 and no output
+func (f *Fake) Script(name string, replies ...Reply) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	key := fakeKey(name)
+	f.replies[key] = append(f.replies[key], replies...)
+}
+
+// Calls returns the commands run so far, in order
+func (f *Fake) Calls() []Command {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return append([]Command(nil), f.calls...)
+}
+
+// CallsTo returns the commands named name run so far
+func (f *Fake) CallsTo(name string) []Command {
+	var r []Command
+	for _, call := range f.Calls() {
+		if fakeKey(call.Name) == fakeKey(name) {
+			r = append(r, call)
+		}
+	}
+	return r
+}
+
+func (f *Fake) Run(ctx context.Context, cmd Command) (Result, error) {
+	reply := f.record(cmd)
+	if err := ctx.Err(); err != nil {
+		return Result{ExitCode: -1}, err
+	}
+	if reply.Err != nil {
+		return Result{ExitCode: -1}, reply.Err
+	}
+	if cmd.Stdout != nil {
+		_, _ = io.WriteString(cmd.Stdout, reply.Stdout)
+	}
+	if cmd.Stderr != nil {
+		_, _ = io.WriteString(cmd.Stderr, reply.Stderr)
+	}
+	return result(cmd, reply.ExitCode)
+}
+
+func (f *Fake) record(cmd Command) Reply {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.calls = append(f.calls, cmd)
+
+	key := fakeKey(cmd.Name)
+	replies := f.replies[key]
+	if len(replies) == 0 {
+		return Reply{}
+	}
+	if len(replies) > 1 {
+		f.replies[key] = replies[1:]
+	}
+	return replies[0]
+}
+
+// fakeKey normalizes Windows paths too, as the commands are usually Windows executables
+func fakeKey(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, `\`, "/"))
+	return strings.ToLower(strings.TrimSuffix(name, filepath.Ext(name)))
+}
@@ -1,15 +1,12 @@
 package credentials
 
 import (
-	"fmt"
 	"github.com/satisfactorymodding/SMEI/config"
 	"github.com/satisfactorymodding/SMEI/lib/cfmt"
 	"github.com/satisfactorymodding/SMEI/lib/secret"
-	"log"
 	"os"
 
 	"github.com/fatih/color"
-	"github.com/mircearoata/wwise-cli/lib/wwise/client"
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
 	"golang.org/x/crypto/ssh/terminal"
@@ -20,7 +17,22 @@ type WwiseAuth struct {
 	Password secret.String
 }
 
+// EnsurePassword asks for the password if the secret store needs one and it was not given yet
+func EnsurePassword() error {
+	if config.HasPassword() || !config.Secrets().NeedsPassword() {
+		return nil
+	}
+	return AskForPassword()
+}
+
 func AskForPassword() error {
+	if password, ok := Provided(PasswordInput); ok {
+		return errors.Wrap(config.SetPassword(password), "could not use the provided password")
+	}
+	if IsNonInteractive() && !viper.GetBool(config.DeveloperMode_key) {
+		return NonInteractiveError{Input: PasswordInput}
+	}
+
 	if config.HasLoggedInBefore() {
 		cfmt.Request.Println("If you forgot your password, delete config.yml in '%APPDATA%\\SMEI\\'\nPlease input your password (input is obscured):")
 	} else {
@@ -62,7 +74,11 @@ func passwordLoop() error {
 	return nil
 }
 
-func askForWwiseAuth() error {
+func askForWwiseAuth(authenticator WwiseAuthenticator) error {
+	if IsNonInteractive() {
+		return NonInteractiveError{Input: WwiseEmailInput}
+	}
+
 	cfmt.Request.Print("SMEI needs credentials to your Audiokinetic/Wwise account. " +
 		"If you do not already have one, please navigate to https://www.audiokinetic.com/ and register.\n" +
 		"Please input your account email (input is obscured):\n")
@@ -71,64 +87,171 @@ func askForWwiseAuth() error {
 		return errors.Wrap(err, "could not read the input")
 	}
 	cfmt.Request.Println("Please input your account password (input is obscured): ")
-	return wwisePasswordLoop(string(email))
+	return wwisePasswordLoop(authenticator, string(email))
 }
 
-func wwisePasswordLoop(email string) error {
-	password, err := terminal.ReadPassword(int(os.Stdin.Fd()))
-	if err != nil {
-		return errors.Wrap(err, "could not read a password")
+// How many passwords are tried before giving up, as a network error makes every attempt fail the same way
+const wwiseAttempts = 3
+
+func wwisePasswordLoop(authenticator WwiseAuthenticator, email string) error {
+	for attempt := 1; ; attempt++ {
+		password, err := terminal.ReadPassword(int(os.Stdin.Fd()))
+		if err != nil {
+			return errors.Wrap(err, "could not read a password")
+		}
+
+		err = authenticator.Authenticate(email, string(password))
+		if err == nil {
+			return StoreWwiseCredentials(WwiseAuth{
+				Email:    secret.String(email),
+				Password: secret.String(password),
+			})
+		}
+		if attempt == wwiseAttempts {
+			return errors.Wrapf(err, "could not authenticate after %v attempts", wwiseAttempts)
+		}
+		cfmt.Error.Printf("Authentication failed: %v. Please try again.\n", err)
+	}
+}
+
+// GetWwiseCredentials returns the provided Wwise credentials, or the stored ones. Prompts for them if neither exist
+func GetWwiseCredentials() (*WwiseAuth, error) {
+	email, hasEmail := Provided(WwiseEmailInput)
+	password, hasPassword := Provided(WwisePasswordInput)
+	if hasEmail && hasPassword {
+		return &WwiseAuth{
+			Email:    email,
+			Password: password,
+		}, nil
+	}
+	if hasEmail != hasPassword {
+		return nil, errors.New("both the Wwise email and password must be provided")
 	}
 
-	wwiseClient := client.NewWwiseClient()
-	err = wwiseClient.Authenticate(email, string(password))
+	wwiseEmail, err := config.GetSecretString(config.WwiseEmail_key)
 	if err != nil {
-		fmt.Println("Authentication failed. Please try again.")
-		return wwisePasswordLoop(email)
+		return nil, errors.Wrap(err, "Could not get the Wwise email")
 	}
 
-	if err != nil {
-		return errors.Wrap(err, "could not set the password")
+	if wwiseEmail == "" {
+		err := askForWwiseAuth(NewWwiseAuthenticator())
+		if err != nil {
+			return nil, errors.Wrap(err, "could not log in with Wwise")
+		}
+		wwiseEmail, err = config.GetSecretString(config.WwiseEmail_key)
+		if err != nil {
+			return nil, errors.Wrap(err, "Could not get the Wwise email")
+		}
 	}
 
-	err = config.SetSecretString(config.WwiseEmail_key, secret.String(email))
+	wwisePassword, err := config.GetSecretString(config.WwisePassword_key)
 	if err != nil {
-		return errors.Wrap(err, "could not persist the config change")
+		return nil, errors.Wrap(err, "Could not get the Wwise password")
 	}
 
-	err = config.SetSecretString(config.WwisePassword_key, secret.String(password))
-	if err != nil {
-		return errors.Wrap(err, "could not persist the config change")
+	return &WwiseAuth{
+		Email:    wwiseEmail,
+		Password: wwisePassword,
+	}, nil
+}
+
+// AvailableWwiseCredentials returns the provided Wwise credentials, or the stored ones if they can be read without prompting.
+// Nil if there are none, or if reading them needs the SMEI password which was not entered
+func AvailableWwiseCredentials() (*WwiseAuth, error) {
+	email, hasEmail := Provided(WwiseEmailInput)
+	password, hasPassword := Provided(WwisePasswordInput)
+	if hasEmail && hasPassword {
+		return &WwiseAuth{
+			Email:    email,
+			Password: password,
+		}, nil
 	}
 
-	err = viper.WriteConfig()
+	email, err := config.GetSecretString(config.WwiseEmail_key)
+	if errors.As(err, &config.MissingPasswordError{}) {
+		return nil, nil
+	}
 	if err != nil {
-		return errors.Wrap(err, "could not persist the config change")
+		return nil, errors.Wrap(err, "could not get the Wwise email")
+	}
+	if email == "" {
+		return nil, nil
 	}
+	password, err = config.GetSecretString(config.WwisePassword_key)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get the Wwise password")
+	}
+	return &WwiseAuth{
+		Email:    email,
+		Password: password,
+	}, nil
+}
 
-	return nil
+// SecretsProvided reports whether every stored secret was provided up front, in which case the master password is not needed
+func SecretsProvided() bool {
+	for _, input := range []Input{WwiseEmailInput, WwisePasswordInput, GHTokenInput} {
+		if _, ok := Provided(input); !ok {
+			return false
+		}
+	}
+	return true
 }
 
-func GetWwiseCredentials() (*WwiseAuth, error) {
-	if !viper.IsSet(config.WwiseEmail_key) {
-		err := askForWwiseAuth()
+// ChangePassword prompts for the current and new passwords then changes the password
+func ChangePassword() error {
+	if !config.Secrets().NeedsPassword() {
+		return errors.New("the configured secret store does not use a password")
+	}
+	if !config.HasLoggedInBefore() {
+		return errors.New("no password was set yet")
+	}
+
+	current, ok := Provided(PasswordInput)
+	if !ok {
+		if IsNonInteractive() {
+			return NonInteractiveError{Input: PasswordInput}
+		}
+		cfmt.Request.Println("Please input your current password (input is obscured):")
+		input, err := terminal.ReadPassword(int(os.Stdin.Fd()))
 		if err != nil {
-			log.Panicf("Could not log in with Wwise: %v", err)
+			return errors.Wrap(err, "could not read a password")
 		}
+		current = secret.String(input)
 	}
 
-	wwiseEmail, err := config.GetSecretString(config.WwiseEmail_key)
+	if IsNonInteractive() {
+		return errors.New("the new password can only be input interactively")
+	}
+	newPassword, err := newPasswordLoop()
 	if err != nil {
-		return nil, errors.Wrap(err, "Could not get the Wwise email")
+		return err
 	}
 
-	wwisePassword, err := config.GetSecretString(config.WwisePassword_key)
+	return config.ChangePassword(current, newPassword)
+}
+
+func newPasswordLoop() (secret.String, error) {
+	warning := cfmt.Warning.SprintFunc()
+	cfmt.Request.Fprintf(color.Output, "%s Please input your new password (input is obscured):\n",
+		warning("Please note that there is no way to retrieve this password."))
+	password, err := terminal.ReadPassword(int(os.Stdin.Fd()))
 	if err != nil {
-		return nil, errors.Wrap(err, "Could not get the Wwise password")
+		return "", errors.Wrap(err, "could not read a password")
+	}
+	if len(password) < config.MinPasswordLength {
+		cfmt.Error.Println("Password too short. Please try again.")
+		return newPasswordLoop()
 	}
 
-	return &WwiseAuth{
-		Email:    wwiseEmail,
-		Password: wwisePassword,
-	}, nil
+	cfmt.Request.Println("Please input your new password again:")
+	confirmation, err := terminal.ReadPassword(int(os.Stdin.Fd()))
+	if err != nil {
+		return "", errors.Wrap(err, "could not read a password")
+	}
+	if string(confirmation) != string(password) {
+		cfmt.Error.Println("Passwords do not match. Please try again.")
+		return newPasswordLoop()
+	}
+
+	return secret.String(password), nil
 }
@@ -0,0 +1,121 @@
+package credentials
+
+import (
+	"fmt"
+	"github.com/satisfactorymodding/SMEI/lib/secret"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// Credentials that can be provided up front instead of being prompted for
+type Input struct {
+	// Flag is the CLI flag name. Deliberately different from the config keys. Credential flags are persistent and only local
+	// flags are bound to viper, so that they are never written to the config file
+	Flag string
+	Env  string
+	// FileKey is the key in the credentials file
+	FileKey string
+	Usage   string
+}
+
+var (
+	PasswordInput      = Input{Flag: "password", Env: "SMEI_PASSWORD", FileKey: "password", Usage: "SMEI master password"}
+	WwiseEmailInput    = Input{Flag: "wwise-login-email", Env: "SMEI_WWISE_EMAIL", FileKey: "wwise-email", Usage: "Audiokinetic account email"}
+	WwisePasswordInput = Input{Flag: "wwise-login-password", Env: "SMEI_WWISE_PASSWORD", FileKey: "wwise-password", Usage: "Audiokinetic account password"}
+	GHTokenInput       = Input{Flag: "github-token", Env: "SMEI_GH_TOKEN", FileKey: "gh-token", Usage: "GitHub token with the repo scope"}
+)
+
+var Inputs = []Input{PasswordInput, WwiseEmailInput, WwisePasswordInput, GHTokenInput}
+
+const credentialsFileFlag = "credentials-file"
+const nonInteractiveFlag = "non-interactive"
+
+var provided = map[string]secret.String{}
+var nonInteractive bool
+
+func AddFlags(flags *pflag.FlagSet) {
+	for _, input := range Inputs {
+		flags.String(input.Flag, "", fmt.Sprintf("%v. Can also be set with %v or the credentials file", input.Usage, input.Env))
+	}
+	flags.String(credentialsFileFlag, "", "YAML or JSON file providing "+fileKeys())
+	flags.Bool(nonInteractiveFlag, false, "Fail instead of prompting when a credential is missing. Also set by SMEI_NON_INTERACTIVE")
+}
+
+func fileKeys() string {
+	r := ""
+	for i, input := range Inputs {
+		if i > 0 {
+			r += ", "
+		}
+		r += "'" + input.FileKey + "'"
+	}
+	return r
+}
+
+// Load collects the provided credentials. Flags take precedence over env vars, which take precedence over the credentials file
+func Load(flags *pflag.FlagSet) error {
+	var err error
+	nonInteractive, err = flags.GetBool(nonInteractiveFlag)
+	if err != nil {
+		return err
+	}
+	if os.Getenv("SMEI_NON_INTERACTIVE") != "" {
+		nonInteractive = true
+	}
+
+	file, err := flags.GetString(credentialsFileFlag)
+	if err != nil {
+		return err
+	}
+	if file != "" {
+		v := viper.New()
+		v.SetConfigFile(file)
+		err = v.ReadInConfig()
+		if err != nil {
+			return errors.Wrapf(err, "could not read the credentials file '%v'", file)
+		}
+		for _, input := range Inputs {
+			if v.IsSet(input.FileKey) {
+				provided[input.Flag] = secret.String(v.GetString(input.FileKey))
+			}
+		}
+	}
+
+	for _, input := range Inputs {
+		if value := os.Getenv(input.Env); value != "" {
+			provided[input.Flag] = secret.String(value)
+		}
+		value, err := flags.GetString(input.Flag)
+		if err != nil {
+			return err
+		}
+		if value != "" {
+			provided[input.Flag] = secret.String(value)
+		}
+	}
+
+	return nil
+}
+
+// Provided returns the credential given through a flag, env var or the credentials file
+func Provided(input Input) (secret.String, bool) {
+	value, ok := provided[input.Flag]
+	return value, ok
+}
+
+func IsNonInteractive() bool {
+	return nonInteractive
+}
+
+// NonInteractiveError is returned instead of prompting for a credential in non-interactive mode
+type NonInteractiveError struct {
+	Input Input
+}
+
+func (e NonInteractiveError) Error() string {
+	return fmt.Sprintf("%v is required but SMEI is running non-interactively. Provide it with --%v, %v or the '%v' key of --%v",
+		e.Input.Usage, e.Input.Flag, e.Input.Env, e.Input.FileKey, credentialsFileFlag)
+}
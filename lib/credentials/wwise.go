@@ -0,0 +1,133 @@
+package credentials
+
+import (
+	"github.com/satisfactorymodding/SMEI/config"
+	"strings"
+	"sync"
+
+	"github.com/mircearoata/wwise-cli/lib/wwise/client"
+	"github.com/pkg/errors"
+)
+
+// WwiseAuthenticator is the part of the Wwise client used to check credentials
+type WwiseAuthenticator interface {
+	Authenticate(email string, password string) error
+}
+
+// NewWwiseAuthenticator makes the authenticator used by the credential flows. Replaced by a FakeWwiseClient in tests
+var NewWwiseAuthenticator = func() WwiseAuthenticator {
+	return client.NewWwiseClient()
+}
+
+// FakeWwiseClient accepts the credentials in Accounts and records every authentication attempt
+type FakeWwiseClient struct {
+	Accounts map[string]string
+	Attempts []string
+
+	lock sync.Mutex
+}
+
+func (c *FakeWwiseClient) Authenticate(email string, password string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.Attempts = append(c.Attempts, email)
+
+	expected, ok := c.Accounts[email]
+	if !ok || expected != password {
+		return errors.New("failed to authenticate")
+	}
+	return nil
+}
+
+// StoreWwiseCredentials persists auth through the secret store
+func StoreWwiseCredentials(auth WwiseAuth) error {
+	err := config.SetSecretString(config.WwiseEmail_key, auth.Email)
+	if err != nil {
+		return errors.Wrap(err, "could not persist the config change")
+	}
+
+	err = config.SetSecretString(config.WwisePassword_key, auth.Password)
+	if err != nil {
+		return errors.Wrap(err, "could not persist the config change")
+	}
+
+	err = config.Write()
+	if err != nil {
+		return errors.Wrap(err, "could not persist the config change")
+	}
+
+	return nil
+}
+
+// WwiseLogin checks the credentials with authenticator then stores them. Uses the provided credentials if any,
+// prompts for them otherwise
+func WwiseLogin(authenticator WwiseAuthenticator) error {
+	email, hasEmail := Provided(WwiseEmailInput)
+	password, hasPassword := Provided(WwisePasswordInput)
+	if !hasEmail || !hasPassword {
+		return askForWwiseAuth(authenticator)
+	}
+
+	err := authenticator.Authenticate(string(email), string(password))
+	if err != nil {
+		return errors.Wrap(err, "the provided credentials are invalid")
+	}
+
+	return StoreWwiseCredentials(WwiseAuth{
+		Email:    email,
+		Password: password,
+	})
+}
+
+// WwiseLogout removes the stored credentials
+func WwiseLogout() error {
+	for _, key := range []string{config.WwiseEmail_key, config.WwisePassword_key} {
+		err := config.DeleteSecret(key)
+		if err != nil {
+			return errors.Wrapf(err, "could not delete '%v'", key)
+		}
+		err = config.Unset(key)
+		if err != nil {
+			return errors.Wrapf(err, "could not unset '%v'", key)
+		}
+	}
+	return nil
+}
+
+type WwiseStatus struct {
+	LoggedIn    bool
+	MaskedEmail string
+	// AuthError is why authentication failed, nil if it succeeded
+	AuthError error
+}
+
+// GetWwiseStatus reports the stored credentials and whether they currently authenticate
+func GetWwiseStatus(authenticator WwiseAuthenticator) (WwiseStatus, error) {
+	email, err := config.GetSecretString(config.WwiseEmail_key)
+	if err != nil {
+		return WwiseStatus{}, errors.Wrap(err, "could not get the Wwise email")
+	}
+	if email == "" {
+		return WwiseStatus{}, nil
+	}
+
+	password, err := config.GetSecretString(config.WwisePassword_key)
+	if err != nil {
+		return WwiseStatus{}, errors.Wrap(err, "could not get the Wwise password")
+	}
+
+	return WwiseStatus{
+		LoggedIn:    true,
+		MaskedEmail: MaskEmail(string(email)),
+		AuthError:   authenticator.Authenticate(string(email), string(password)),
+	}, nil
+}
+
+// MaskEmail hides most of the local part of an email, such as j******@example.com
+func MaskEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return strings.Repeat("*", len(email))
+	}
+	return email[:1] + strings.Repeat("*", at-1) + email[at:]
+}
@@ -0,0 +1,135 @@
+package credentials
+
+import (
+	"github.com/satisfactorymodding/SMEI/config"
+	"github.com/satisfactorymodding/SMEI/lib/secret"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	testEmail    = "pioneer@example.com"
+	testPassword = "ficsit-password"
+)
+
+// useMemoryStore keeps the secrets in memory and the config in a temporary file
+func useMemoryStore(t *testing.T) *config.MemoryStore {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(configFile, nil, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	viper.SetConfigFile(configFile)
+	store := config.NewMemoryStore()
+	config.SetSecretStore(store)
+	t.Cleanup(func() {
+		viper.Reset()
+		config.SetSecretStore(nil)
+		provided = map[string]secret.String{}
+	})
+	return store
+}
+
+func TestGetWwiseStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		password secret.String
+		loggedIn bool
+		authOK   bool
+	}{
+		{name: "good credentials", password: testPassword, loggedIn: true, authOK: true},
+		{name: "bad credentials", password: "wrong-password", loggedIn: true},
+		{name: "no credentials"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := useMemoryStore(t)
+			if tt.loggedIn {
+				_ = store.Set(config.WwiseEmail_key, testEmail)
+				_ = store.Set(config.WwisePassword_key, tt.password)
+			}
+			client := &FakeWwiseClient{Accounts: map[string]string{testEmail: testPassword}}
+
+			status, err := GetWwiseStatus(client)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if status.LoggedIn != tt.loggedIn {
+				t.Fatalf("LoggedIn = %v, want %v", status.LoggedIn, tt.loggedIn)
+			}
+			if !tt.loggedIn {
+				if len(client.Attempts) != 0 {
+					t.Errorf("authenticated %v times without credentials", len(client.Attempts))
+				}
+				return
+			}
+			if authOK := status.AuthError == nil; authOK != tt.authOK {
+				t.Errorf("AuthError = %v", status.AuthError)
+			}
+			if status.MaskedEmail != "p******@example.com" {
+				t.Errorf("MaskedEmail = %v", status.MaskedEmail)
+			}
+		})
+	}
+}
+
+func TestWwiseLogin(t *testing.T) {
+	tests := []struct {
+		name     string
+		password secret.String
+		stored   bool
+	}{
+		{name: "good credentials", password: testPassword, stored: true},
+		{name: "bad credentials", password: "wrong-password"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := useMemoryStore(t)
+			provided[WwiseEmailInput.Flag] = testEmail
+			provided[WwisePasswordInput.Flag] = tt.password
+			client := &FakeWwiseClient{Accounts: map[string]string{testEmail: testPassword}}
+
+			err := WwiseLogin(client)
+			if tt.stored != (err == nil) {
+				t.Fatalf("WwiseLogin() error = %v", err)
+			}
+
+			email, _ := store.Get(config.WwiseEmail_key)
+			password, _ := store.Get(config.WwisePassword_key)
+			if stored := email != "" || password != ""; stored != tt.stored {
+				t.Errorf("credentials stored = %v, want %v", stored, tt.stored)
+			}
+			if tt.stored && (email != testEmail || password != tt.password) {
+				t.Errorf("stored %v, want the provided credentials", string(email))
+			}
+		})
+	}
+}
+
+func TestAvailableWwiseCredentials(t *testing.T) {
+	store := useMemoryStore(t)
+	auth, err := AvailableWwiseCredentials()
+	if err != nil || auth != nil {
+		t.Fatalf("AvailableWwiseCredentials() = %v, %v without stored credentials, want nothing", auth, err)
+	}
+
+	_ = store.Set(config.WwiseEmail_key, testEmail)
+	_ = store.Set(config.WwisePassword_key, testPassword)
+	auth, err = AvailableWwiseCredentials()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if auth == nil || auth.Email != testEmail || auth.Password != testPassword {
+		t.Errorf("AvailableWwiseCredentials() did not return the stored credentials")
+	}
+
+	// The encrypted store cannot be read before the password is entered
+	config.SetSecretStore(nil)
+	auth, err = AvailableWwiseCredentials()
+	if err != nil || auth != nil {
+		t.Errorf("AvailableWwiseCredentials() = %v, %v without the password, want nothing", auth, err)
+	}
+}
@@ -6,30 +6,112 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"github.com/pkg/errors"
+	"golang.org/x/crypto/scrypt"
 	"io"
+	"strings"
 )
 
+// Values encrypted with the current format start with this prefix. '$' is not part of the base64 alphabet,
+// so legacy values can never be mistaken for current ones
+const v2Prefix = "v2$"
+
+const saltSize = 16
+
+// scrypt parameters recommended for interactive logins
+const (
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+var ErrDecryption = errors.New("could not decrypt: wrong key or tampered data")
+
+// Encrypt encrypts value with AES-GCM, using a key derived from the given one with scrypt and a random salt
 func Encrypt(key, value string) (string, error) {
-	b := stringTo32B(key)
-	block, err := aes.NewCipher(b)
+	salt := make([]byte, saltSize)
+	_, err := io.ReadFull(rand.Reader, salt)
 	if err != nil {
-		return "", errors.Wrap(err, "could not create a new cipher")
+		return "", errors.New("could not get random bytes")
 	}
 
-	cipherText := make([]byte, aes.BlockSize+len(value))
+	gcm, err := newGCM(key, salt)
+	if err != nil {
+		return "", err
+	}
 
-	iv := cipherText[:aes.BlockSize]
-	_, err = io.ReadFull(rand.Reader, iv)
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = io.ReadFull(rand.Reader, nonce)
 	if err != nil {
 		return "", errors.New("could not get random bytes")
 	}
 
-	stream := cipher.NewCFBEncrypter(block, iv)
-	stream.XORKeyStream(cipherText[aes.BlockSize:], []byte(value))
+	data := append(salt, nonce...)
+	data = gcm.Seal(data, nonce, []byte(value), nil)
 
-	return base64.RawStdEncoding.EncodeToString(cipherText), nil
+	return v2Prefix + base64.RawStdEncoding.EncodeToString(data), nil
 }
 
+// Decrypt decrypts values of any format. Returns ErrDecryption if key is wrong or value was tampered with,
+// which can only be detected for the current format
+func Decrypt(key, value string) (string, error) {
+	if IsLegacy(value) {
+		return decryptLegacy(key, value)
+	}
+
+	data, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(value, v2Prefix))
+	if err != nil {
+		return "", errors.Wrap(err, "unable to decode value from base64")
+	}
+
+	if len(data) < saltSize {
+		return "", errors.New("cipher text was too short")
+	}
+	salt := data[:saltSize]
+	data = data[saltSize:]
+
+	gcm, err := newGCM(key, salt)
+	if err != nil {
+		return "", err
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return "", errors.New("cipher text was too short")
+	}
+	nonce := data[:gcm.NonceSize()]
+	data = data[gcm.NonceSize():]
+
+	plainText, err := gcm.Open(nil, nonce, data, nil)
+	if err != nil {
+		return "", ErrDecryption
+	}
+
+	return string(plainText), nil
+}
+
+// IsLegacy reports whether value was encrypted with the legacy format and should be encrypted again
+func IsLegacy(value string) bool {
+	return !strings.HasPrefix(value, v2Prefix)
+}
+
+func newGCM(key string, salt []byte) (cipher.AEAD, error) {
+	derived, err := scrypt.Key([]byte(key), salt, scryptN, scryptR, scryptP, 32)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not derive the key")
+	}
+
+	block, err := aes.NewCipher(derived)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create a new cipher")
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create the GCM")
+	}
+	return gcm, nil
+}
+
+// The legacy format is AES-CFB with the key repeated to 32 bytes. It is only kept to migrate existing values
 func stringTo32B(str string) []byte {
 	var r []byte
 	for len(r) < 32 {
@@ -38,7 +120,7 @@ func stringTo32B(str string) []byte {
 	return r[:32]
 }
 
-func Decrypt(key, value string) (string, error) {
+func decryptLegacy(key, value string) (string, error) {
 	cipherText, err := base64.RawStdEncoding.DecodeString(value)
 
 	if err != nil {
@@ -62,4 +144,4 @@ func Decrypt(key, value string) (string, error) {
 	stream.XORKeyStream(cipherText, cipherText)
 
 	return string(cipherText), nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,109 @@
+package crypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	testKey   = "correct horse"
+	testValue = "ficsit-password"
+)
+
+func TestRoundTrip(t *testing.T) {
+	encrypted, err := Encrypt(testKey, testValue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if IsLegacy(encrypted) {
+		t.Errorf("IsLegacy(%q) = true for the current format", encrypted)
+	}
+	if strings.Contains(encrypted, testValue) {
+		t.Errorf("Encrypt() = %q contains the plain value", encrypted)
+	}
+
+	decrypted, err := Decrypt(testKey, encrypted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decrypted != testValue {
+		t.Errorf("Decrypt() = %q, want %q", decrypted, testValue)
+	}
+}
+
+func TestEncryptIsRandomized(t *testing.T) {
+	first, err := Encrypt(testKey, testValue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := Encrypt(testKey, testValue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == second {
+		t.Errorf("Encrypt() returned %q twice, want a different salt and nonce each time", first)
+	}
+}
+
+func TestDecryptFailures(t *testing.T) {
+	encrypted, err := Encrypt(testKey, testValue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(encrypted, v2Prefix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[len(data)-1] ^= 1
+	tampered := v2Prefix + base64.RawStdEncoding.EncodeToString(data)
+
+	tests := []struct {
+		name  string
+		key   string
+		value string
+	}{
+		{name: "wrong key", key: "wrong horse", value: encrypted},
+		{name: "tampered cipher text", key: testKey, value: tampered},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decrypted, err := Decrypt(tt.key, tt.value)
+			if !errors.Is(err, ErrDecryption) {
+				t.Errorf("Decrypt() = %q, %v, want ErrDecryption", decrypted, err)
+			}
+		})
+	}
+}
+
+func TestDecryptLegacy(t *testing.T) {
+	// Encrypt the way the legacy format did, with a zero IV to keep the value stable
+	block, err := aes.NewCipher(stringTo32B(testKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := make([]byte, aes.BlockSize+len(testValue))
+	cipher.NewCFBEncrypter(block, data[:aes.BlockSize]).XORKeyStream(data[aes.BlockSize:], []byte(testValue))
+	legacy := base64.RawStdEncoding.EncodeToString(data)
+
+	if !IsLegacy(legacy) {
+		t.Fatalf("IsLegacy(%q) = false for the legacy format", legacy)
+	}
+
+	decrypted, err := Decrypt(testKey, legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decrypted != testValue {
+		t.Errorf("Decrypt() = %q, want %q", decrypted, testValue)
+	}
+
+	_, err = decryptLegacy(testKey, base64.RawStdEncoding.EncodeToString([]byte("short")))
+	if err == nil {
+		t.Errorf("decryptLegacy() of a value shorter than the IV did not fail")
+	}
+}
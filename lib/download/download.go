@@ -0,0 +1,326 @@
+package download
+
+import (
+	"context"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/satisfactorymodding/SMEI/lib/runlog"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const partSuffix = ".part"
+
+const (
+	defaultRetries = 5
+	firstBackoff   = time.Second
+	maxBackoff     = 30 * time.Second
+)
+
+var ErrChecksum = errors.New("the downloaded file does not match its checksum")
+
+// Request describes a file to download
+type Request struct {
+	URL string
+	// Resolve gets the URL before every attempt if set, for URLs that expire such as signed redirects
+	Resolve func(ctx context.Context) (string, error)
+	// Path is where the file ends up. It is only written once the download is complete and verified
+	Path string
+	// Size is the expected size in bytes, unknown if not positive
+	Size int64
+	// Digest is the expected checksum as "<algorithm>:<hex>". sha256 and sha1 are supported. Not checked if empty
+	Digest string
+	Header http.Header
+	// Client defaults to http.DefaultClient
+	Client *http.Client
+	// Retries is how many times a failed attempt is retried. Defaults to 5
+	Retries int
+	// Progress is called with how many bytes of the file are downloaded, including the ones resumed. Optional
+	Progress func(done int64)
+}
+
+// progressWriter reports the bytes written through it
+type progressWriter struct {
+	w        io.Writer
+	done     int64
+	progress func(done int64)
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.done += int64(n)
+	p.progress(p.done)
+	return n, err
+}
+
+// statusError is returned for unexpected HTTP statuses
+type statusError struct {
+	status int
+}
+
+func (e statusError) Error() string {
+	return fmt.Sprintf("unexpected status %v", e.status)
+}
+
+// Download streams the file to a .part file next to the destination, resuming it with HTTP Range if it already exists.
+// Failed attempts are retried with exponential backoff. Once complete, the size and digest are verified and the file is renamed
+// to its destination. Nothing is downloaded if the destination already exists and is verified
+func Download(ctx context.Context, req Request) error {
+	if req.Client == nil {
+		req.Client = http.DefaultClient
+	}
+	if req.Retries == 0 {
+		req.Retries = defaultRetries
+	}
+
+	if req.Size > 0 {
+		err := Verify(req.Path, req.Size, req.Digest)
+		if err == nil {
+			return nil
+		}
+	}
+
+	err := os.MkdirAll(filepath.Dir(req.Path), 0755)
+	if err != nil {
+		return errors.Wrap(err, "could not create the destination directory")
+	}
+
+	backoff := firstBackoff
+	for attempt := 0; ; attempt++ {
+		err = try(ctx, req)
+		if err == nil {
+			break
+		}
+		if attempt >= req.Retries || !retryable(err, req) || ctx.Err() != nil {
+			return err
+		}
+		runlog.Debugf("Downloading '%v' failed, retrying in %v: %v", filepath.Base(req.Path), backoff, err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+
+	err = os.Rename(req.Path+partSuffix, req.Path)
+	if err != nil {
+		return errors.Wrap(err, "could not move the downloaded file to its destination")
+	}
+	return nil
+}
+
+// try makes one attempt at completing the .part file and verifies it
+func try(ctx context.Context, req Request) error {
+	part := req.Path + partSuffix
+	url := req.URL
+	if req.Resolve != nil {
+		var err error
+		url, err = req.Resolve(ctx)
+		if err != nil {
+			return errors.Wrap(err, "could not get the download URL")
+		}
+	}
+
+	offset, err := partSize(part)
+	if err != nil {
+		return err
+	}
+	if req.Size > 0 && offset == req.Size {
+		return verifyPart(req)
+	}
+	if req.Size > 0 && offset > req.Size {
+		offset = 0
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return errors.Wrap(err, "could not make the request")
+	}
+	for name, values := range req.Header {
+		httpReq.Header[name] = values
+	}
+	if offset > 0 {
+		httpReq.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := req.Client.Do(httpReq)
+	if err != nil {
+		return errors.Wrap(err, "could not send the request")
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		start, err := rangeStart(resp.Header.Get("Content-Range"))
+		if err != nil || start != offset {
+			// Appending would corrupt the file, start over on the next attempt
+			_ = os.Remove(part)
+			return errors.Errorf("the server resumed the download at '%v' instead of byte %v", resp.Header.Get("Content-Range"), offset)
+		}
+		flags |= os.O_APPEND
+	case http.StatusOK:
+		// The server ignored the range, start over
+		flags |= os.O_TRUNC
+		offset = 0
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The part is probably already complete but its size was unknown
+		return verifyPart(req)
+	default:
+		return statusError{status: resp.StatusCode}
+	}
+
+	file, err := os.OpenFile(part, flags, 0644)
+	if err != nil {
+		return errors.Wrap(err, "could not open the partial file")
+	}
+	var w io.Writer = file
+	if req.Progress != nil {
+		req.Progress(offset)
+		w = &progressWriter{w: file, done: offset, progress: req.Progress}
+	}
+	_, err = io.Copy(w, resp.Body)
+	closeErr := file.Close()
+	if err != nil {
+		return errors.Wrap(err, "could not download the file")
+	}
+	if closeErr != nil {
+		return errors.Wrap(closeErr, "could not write the partial file")
+	}
+
+	// Keep the partial file so that the next attempt resumes it
+	offset, err = partSize(part)
+	if err != nil {
+		return err
+	}
+	if req.Size > 0 && offset < req.Size {
+		return errors.Errorf("the download ended after %v of %v bytes", offset, req.Size)
+	}
+
+	return verifyPart(req)
+}
+
+// verifyPart removes the .part file if it is invalid, so that the next attempt starts over
+func verifyPart(req Request) error {
+	part := req.Path + partSuffix
+	err := Verify(part, req.Size, req.Digest)
+	if err != nil {
+		_ = os.Remove(part)
+	}
+	return err
+}
+
+// Verify checks that the file at path has the given size and digest. Either is not checked if it is empty
+func Verify(path string, size int64, digest string) error {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if size > 0 && stat.Size() != size {
+		return errors.Errorf("expected %v bytes but got %v", size, stat.Size())
+	}
+	if digest == "" {
+		return nil
+	}
+
+	algorithm, expected, ok := strings.Cut(digest, ":")
+	if !ok {
+		return errors.Errorf("invalid digest '%v'", digest)
+	}
+	h, err := newHash(algorithm)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return errors.Wrap(err, "could not open the file to verify")
+	}
+	defer file.Close()
+	_, err = io.Copy(h, file)
+	if err != nil {
+		return errors.Wrap(err, "could not read the file to verify")
+	}
+
+	if !strings.EqualFold(hex.EncodeToString(h.Sum(nil)), expected) {
+		return ErrChecksum
+	}
+	return nil
+}
+
+// Digest computes the sha256 digest of the file at path, in the format of Request.Digest
+func Digest(path string) (string, error) {
+	h := sha256.New()
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	_, err = io.Copy(h, file)
+	if err != nil {
+		return "", err
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func newHash(algorithm string) (hash.Hash, error) {
+	switch strings.ToLower(algorithm) {
+	case "sha256":
+		return sha256.New(), nil
+	case "sha1":
+		return sha1.New(), nil
+	}
+	return nil, errors.Errorf("unsupported digest algorithm '%v'", algorithm)
+}
+
+// rangeStart returns the first byte of a Content-Range header such as "bytes 100-199/200"
+func rangeStart(contentRange string) (int64, error) {
+	unit, rest, ok := strings.Cut(contentRange, " ")
+	if !ok || unit != "bytes" {
+		return 0, errors.Errorf("invalid content range '%v'", contentRange)
+	}
+	start, _, ok := strings.Cut(rest, "-")
+	if !ok {
+		return 0, errors.Errorf("invalid content range '%v'", contentRange)
+	}
+	return strconv.ParseInt(start, 10, 64)
+}
+
+func partSize(part string) (int64, error) {
+	stat, err := os.Stat(part)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, "could not check the partial file")
+	}
+	return stat.Size(), nil
+}
+
+// retryable reports whether another attempt could succeed. Client errors are final, unless the URL is resolved again.
+// Checksum mismatches are final too, the server would most likely send the same file again
+func retryable(err error, req Request) bool {
+	if errors.Is(err, ErrChecksum) {
+		return false
+	}
+	var status statusError
+	if errors.As(err, &status) && status.status >= 400 && status.status < 500 {
+		return req.Resolve != nil || status.status == http.StatusRequestTimeout || status.status == http.StatusTooManyRequests
+	}
+	return true
+}
@@ -0,0 +1,97 @@
+package download
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+var content = []byte("the quick brown fox jumps over the lazy dog")
+
+// serve serves content, resuming at the requested offset plus skew
+func serve(t *testing.T, skew int64) (*httptest.Server, *int32) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		offset := int64(0)
+		if header := r.Header.Get("Range"); header != "" {
+			offset, _ = strconv.ParseInt(strings.TrimSuffix(strings.TrimPrefix(header, "bytes="), "-"), 10, 64)
+			offset += skew
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, len(content)-1, len(content)))
+			w.WriteHeader(http.StatusPartialContent)
+		}
+		_, _ = w.Write(content[offset:])
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func sha256Digest(b []byte) string {
+	sum := sha256.Sum256(b)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func TestDownload(t *testing.T) {
+	tests := []struct {
+		name   string
+		part   []byte
+		skew   int64
+		digest string
+		// requests is how many requests are expected, 0 if the download fails
+		requests int32
+		failed   bool
+	}{
+		{name: "new download", digest: sha256Digest(content), requests: 1},
+		{name: "resumed download", part: content[:10], digest: sha256Digest(content), requests: 1},
+		{name: "resumed at the wrong offset", part: content[:10], skew: 5, digest: sha256Digest(content), requests: 2},
+		{name: "checksum mismatch", digest: sha256Digest([]byte("something else")), requests: 1, failed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := serve(t, tt.skew)
+			path := filepath.Join(t.TempDir(), "file")
+			if tt.part != nil {
+				err := os.WriteFile(path+partSuffix, tt.part, 0644)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := Download(context.Background(), Request{URL: server.URL, Path: path, Size: int64(len(content)), Digest: tt.digest})
+			if tt.failed != (err != nil) {
+				t.Fatalf("Download() error = %v", err)
+			}
+			if got := atomic.LoadInt32(requests); got != tt.requests {
+				t.Errorf("made %v requests, want %v", got, tt.requests)
+			}
+
+			if tt.failed {
+				if !errors.Is(err, ErrChecksum) {
+					t.Errorf("Download() error = %v, want ErrChecksum", err)
+				}
+				if _, err := os.Stat(path + partSuffix); !os.IsNotExist(err) {
+					t.Error("the invalid partial file was kept")
+				}
+				return
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != string(content) {
+				t.Errorf("downloaded %q, want %q", data, content)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package download
+
+import (
+	"context"
+	"sync"
+)
+
+// Job is a unit of work run by Parallel. It must stop when ctx is cancelled
+type Job func(ctx context.Context) error
+
+// Parallel runs jobs with at most concurrency of them at once. The first error cancels the other jobs and is returned
+func Parallel(ctx context.Context, concurrency int, jobs []Job) error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	queue := make(chan Job)
+	for i := 0; i < concurrency && i < len(jobs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range queue {
+				err := job(ctx)
+				if err == nil {
+					continue
+				}
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, job := range jobs {
+		if ctx.Err() != nil {
+			break
+		}
+		select {
+		case queue <- job:
+		case <-ctx.Done():
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
@@ -1,11 +1,13 @@
 package elevate
 
 import (
+	"context"
 	"fmt"
 	"github.com/pkg/errors"
+	"github.com/satisfactorymodding/SMEI/lib/command"
+	"github.com/satisfactorymodding/SMEI/lib/credentials"
 	"log"
 	"os"
-	"os/exec"
 	"strings"
 )
 
@@ -27,8 +29,8 @@ func IsElevated() bool {
 // Reruns the current executable. Call is final and will always exit
 func RerunElevatedFinal() {
 	err := RerunElevated()
-	if err, ok := err.(*exec.ExitError); ok {
-		os.Exit(err.ExitCode())
+	if code := command.ExitCode(err); code != -1 {
+		os.Exit(code)
 	}
 	if err != nil {
 		log.Fatal(err)
@@ -36,7 +38,7 @@ func RerunElevatedFinal() {
 	os.Exit(0)
 }
 
-// Reruns the current command with 1-to-1 arguments but elevated. May return the error from exec.Cmd's Run method
+// Reruns the current command with 1-to-1 arguments but elevated. May return a command.ExitError with the exit code of the elevated process
 func RerunElevated() error {
 	self, err := os.Executable()
 	if err != nil {
@@ -45,10 +47,23 @@ func RerunElevated() error {
 	cmdArgs := fmt.Sprintf(
 		`Start-Process -Wait -Verb RunAs -FilePath '%v' -ArgumentList '%v'`,
 		self, strings.Join(os.Args[1:], " "))
-	command := exec.Command("powershell", "-Command", cmdArgs)
-	fmt.Println(command)
-	command.Stdout = os.Stdout
-	command.Stderr = os.Stderr
+	_, err = command.Run(context.Background(), command.Command{
+		Name:    "powershell",
+		Args:    []string{"-Command", cmdArgs},
+		Stdout:  os.Stdout,
+		Stderr:  os.Stderr,
+		Secrets: providedSecrets(),
+	})
+	return err
+}
 
-	return command.Run()
+// providedSecrets are the credentials passed on the command line, which are passed again to the elevated process
+func providedSecrets() []string {
+	var r []string
+	for _, input := range credentials.Inputs {
+		if value, ok := credentials.Provided(input); ok {
+			r = append(r, string(value))
+		}
+	}
+	return r
 }
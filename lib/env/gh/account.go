@@ -0,0 +1,110 @@
+package gh
+
+import (
+	"context"
+	"github.com/satisfactorymodding/SMEI/config"
+	"github.com/satisfactorymodding/SMEI/lib/credentials"
+	"github.com/satisfactorymodding/SMEI/lib/secret"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v42/github"
+	"github.com/pkg/errors"
+)
+
+// RequiredScope is needed to download the releases of the private Unreal Engine fork
+const RequiredScope = "repo"
+
+type Status struct {
+	Login string
+	// Scopes of the token. Nil for tokens that do not report scopes, such as fine-grained personal access tokens
+	Scopes []string
+}
+
+// HasRequiredScope reports whether the token can read private repositories. Tokens that do not report scopes are assumed to
+func (s *Status) HasRequiredScope() bool {
+	if s.Scopes == nil {
+		return true
+	}
+	for _, scope := range s.Scopes {
+		if scope == RequiredScope {
+			return true
+		}
+	}
+	return false
+}
+
+// GetStatus returns the user client is authenticated as and the scopes of its token
+func GetStatus(ctx context.Context, client *github.Client) (*Status, error) {
+	user, resp, err := client.Users.Get(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	status := &Status{Login: user.GetLogin()}
+	if header, ok := resp.Header["X-Oauth-Scopes"]; ok {
+		status.Scopes = []string{}
+		for _, scope := range strings.Split(strings.Join(header, ","), ",") {
+			scope = strings.TrimSpace(scope)
+			if scope != "" {
+				status.Scopes = append(status.Scopes, scope)
+			}
+		}
+	}
+	return status, nil
+}
+
+// IsUnauthorized reports whether err was caused by an expired or revoked token
+func IsUnauthorized(err error) bool {
+	var responseErr *github.ErrorResponse
+	return errors.As(err, &responseErr) && responseErr.Response != nil && responseErr.Response.StatusCode == http.StatusUnauthorized
+}
+
+// Login authenticates with the device flow and stores the new token, replacing the stored one
+func Login() (secret.String, error) {
+	token, err := deviceFlow()
+	if err != nil {
+		return "", err
+	}
+
+	err = saveToken(token)
+	if err != nil {
+		return "", errors.Wrap(err, "error saving token")
+	}
+	err = config.Write()
+	if err != nil {
+		return "", errors.Wrap(err, "could not persist the config change")
+	}
+	return token, nil
+}
+
+// Logout removes the stored token
+func Logout() error {
+	accessToken = ""
+	err := config.DeleteSecret(config.GHToken_key)
+	if err != nil {
+		return errors.Wrap(err, "could not delete the token")
+	}
+	return config.Unset(config.GHToken_key)
+}
+
+// StoredToken returns the provided or stored token without logging in. Empty if there is none
+func StoredToken() (secret.String, error) {
+	if token, ok := credentials.Provided(credentials.GHTokenInput); ok {
+		return token, nil
+	}
+	token, err := config.GetSecretString(config.GHToken_key)
+	if err != nil {
+		return "", errors.Wrap(err, "error getting the gh token")
+	}
+	return token, nil
+}
+
+// AvailableToken is StoredToken, but empty instead of an error when reading the stored token needs the SMEI password which was not entered
+func AvailableToken() (secret.String, error) {
+	token, err := StoredToken()
+	if errors.As(err, &config.MissingPasswordError{}) {
+		return "", nil
+	}
+	return token, err
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 	"github.com/satisfactorymodding/SMEI/config"
 	"github.com/satisfactorymodding/SMEI/lib/cfmt"
+	"github.com/satisfactorymodding/SMEI/lib/credentials"
 	"github.com/satisfactorymodding/SMEI/lib/secret"
+	"net/url"
+	"strings"
 
 	"gg-scm.io/pkg/ghdevice"
 	"github.com/google/go-github/v42/github"
@@ -15,19 +18,40 @@ import (
 
 var accessToken secret.String
 
+// BaseURL replaces the GitHub API URL if not empty, such as with the URL of an httptest server
+var BaseURL string
+
+// Flow runs the device flow. Can be replaced so that no browser is needed
+var Flow = ghdevice.Flow
+
 func AuthedClient(ctx context.Context) (*github.Client, error) {
 	token, err := GetToken()
 	if err != nil {
 		return nil, errors.Wrap(err, "could not get an auth accessToken")
 	}
 
-	return makeGithubClient(ctx, string(token)), nil
+	return NewClient(ctx, string(token)), nil
 }
 
+// GetToken returns the provided token, or the stored one if GitHub accepts it. Logs in again only if there is no stored token
+// or GitHub rejects it. Personal access tokens, including fine-grained ones, can be provided with SMEI_GH_TOKEN
 func GetToken() (secret.String, error) {
 	if accessToken != "" {
 		return accessToken, nil
 	}
+	ctx := context.Background()
+
+	if token, ok := credentials.Provided(credentials.GHTokenInput); ok {
+		err := validate(ctx, token)
+		if IsUnauthorized(err) {
+			return "", errors.New("the provided GitHub token expired or was revoked")
+		}
+		if err != nil {
+			cfmt.Warning.Printf("Could not check the provided GitHub token, using it anyway: %v\n", err)
+		}
+		accessToken = token
+		return accessToken, nil
+	}
 
 	token, err := config.GetSecretString(config.GHToken_key)
 	if err != nil {
@@ -35,24 +59,51 @@ func GetToken() (secret.String, error) {
 	}
 
 	if token != "" {
-		err = saveToken(token)
-		if err != nil {
-			return "", errors.Wrap(err, "error saving token")
+		err = validate(ctx, token)
+		if err == nil {
+			accessToken = token
+			return accessToken, nil
 		}
+		if !IsUnauthorized(err) {
+			// Most likely a network issue, which logging in again would not fix
+			cfmt.Warning.Printf("Could not check the stored GitHub token, using it anyway: %v\n", err)
+			accessToken = token
+			return accessToken, nil
+		}
+		cfmt.Warning.Println("The stored GitHub token expired or was revoked. Logging in again")
+	}
+
+	// The device flow needs a human
+	if credentials.IsNonInteractive() {
+		return "", credentials.NonInteractiveError{Input: credentials.GHTokenInput}
 	}
 
+	return Login()
+}
+
+// validate checks token with a cheap API call. Use IsUnauthorized on the error to know whether GitHub rejected the token
+func validate(ctx context.Context, token secret.String) error {
+	status, err := GetStatus(ctx, NewClient(ctx, string(token)))
+	if err != nil {
+		return err
+	}
+	if !status.HasRequiredScope() {
+		cfmt.Warning.Printf("The GitHub token of %v does not have the '%v' scope. Run 'smei config github login' if downloads fail\n", status.Login, RequiredScope)
+	}
+	return nil
+}
+
+func deviceFlow() (secret.String, error) {
 	opt := ghdevice.Options{
-		ClientID: viper.GetString("GH-client-id"),
+		ClientID: viper.GetString(config.GHClientID_key),
 		Prompter: prompter,
-		Scopes:   []string{"repo"},
+		Scopes:   []string{RequiredScope},
 	}
-	newToken, err := ghdevice.Flow(context.Background(), opt)
-	token = secret.String(newToken)
-	err = saveToken(token)
+	token, err := Flow(context.Background(), opt)
 	if err != nil {
-		return "", errors.Wrap(err, "error saving token")
+		return "", errors.Wrap(err, "could not authenticate with GitHub")
 	}
-	return accessToken, err
+	return secret.String(token), nil
 }
 
 func prompter(ctx context.Context, prompt ghdevice.Prompt) error {
@@ -60,10 +111,18 @@ func prompter(ctx context.Context, prompt ghdevice.Prompt) error {
 	return nil
 }
 
-func makeGithubClient(ctx context.Context, accessToken string) *github.Client {
+// NewClient makes a client authenticated with accessToken
+func NewClient(ctx context.Context, accessToken string) *github.Client {
 	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: accessToken})
 	tc := oauth2.NewClient(ctx, ts)
-	return github.NewClient(tc)
+	client := github.NewClient(tc)
+	if BaseURL != "" {
+		baseURL, err := url.Parse(strings.TrimSuffix(BaseURL, "/") + "/")
+		if err == nil {
+			client.BaseURL = baseURL
+		}
+	}
+	return client
 }
 
 func saveToken(token secret.String) error {
@@ -0,0 +1,121 @@
+package gh
+
+import (
+	"context"
+	"fmt"
+	"github.com/satisfactorymodding/SMEI/config"
+	"github.com/satisfactorymodding/SMEI/lib/secret"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gg-scm.io/pkg/ghdevice"
+	"github.com/spf13/viper"
+)
+
+// useFakeGitHub serves the user endpoint, accepting only the valid tokens, and replaces the config, the secret store and the device flow.
+// The returned counter is incremented every time the device flow runs, which then returns newToken
+func useFakeGitHub(t *testing.T, store *config.MemoryStore, newToken string, valid ...string) *int {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, token := range valid {
+			if r.Header.Get("Authorization") == "Bearer "+token {
+				w.Header().Set("X-OAuth-Scopes", RequiredScope)
+				fmt.Fprint(w, `{"login":"pioneer"}`)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"message":"Bad credentials"}`)
+	}))
+
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(configFile, nil, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	viper.SetConfigFile(configFile)
+	config.SetSecretStore(store)
+
+	logins := 0
+	BaseURL = server.URL
+	Flow = func(ctx context.Context, opts ghdevice.Options) (string, error) {
+		logins++
+		return newToken, nil
+	}
+
+	t.Cleanup(func() {
+		server.Close()
+		viper.Reset()
+		config.SetSecretStore(nil)
+		accessToken = ""
+		BaseURL = ""
+		Flow = ghdevice.Flow
+	})
+	return &logins
+}
+
+func TestGetToken(t *testing.T) {
+	tests := []struct {
+		name   string
+		stored secret.String
+		want   secret.String
+		logins int
+	}{
+		{name: "valid stored token is reused", stored: "valid", want: "valid"},
+		{name: "revoked stored token logs in again", stored: "revoked", want: "new", logins: 1},
+		{name: "no stored token logs in", want: "new", logins: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := config.NewMemoryStore()
+			logins := useFakeGitHub(t, store, "new", "valid", "new")
+			if tt.stored != "" {
+				_ = store.Set(config.GHToken_key, tt.stored)
+			}
+
+			token, err := GetToken()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if token != tt.want {
+				t.Errorf("GetToken() = %v, want %v", string(token), string(tt.want))
+			}
+			if *logins != tt.logins {
+				t.Errorf("logged in %v times, want %v", *logins, tt.logins)
+			}
+			stored, _ := store.Get(config.GHToken_key)
+			if stored != tt.want {
+				t.Errorf("stored token = %v, want %v", string(stored), string(tt.want))
+			}
+		})
+	}
+}
+
+func TestLogout(t *testing.T) {
+	store := config.NewMemoryStore()
+	logins := useFakeGitHub(t, store, "new", "valid", "new")
+	_ = store.Set(config.GHToken_key, "valid")
+	_, err := GetToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Logout()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, _ := store.Get(config.GHToken_key)
+	if stored != "" {
+		t.Errorf("stored token = %v after logging out", string(stored))
+	}
+
+	token, err := GetToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "new" || *logins != 1 {
+		t.Errorf("GetToken() = %v after %v logins, want a new login", string(token), *logins)
+	}
+}
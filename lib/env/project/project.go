@@ -1,15 +1,24 @@
 package project
 
 import (
+	"context"
 	"fmt"
 	"github.com/satisfactorymodding/SMEI/config"
 	"github.com/satisfactorymodding/SMEI/lib/cfmt"
+	"github.com/satisfactorymodding/SMEI/lib/command"
 	"github.com/satisfactorymodding/SMEI/lib/credentials"
+	wwiseversions "github.com/satisfactorymodding/SMEI/lib/env/wwise"
+	"github.com/satisfactorymodding/SMEI/lib/plan"
+	"github.com/satisfactorymodding/SMEI/lib/progress"
+	"github.com/satisfactorymodding/SMEI/lib/state"
+	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/mircearoata/wwise-cli/lib/unrealengine"
 	"github.com/mircearoata/wwise-cli/lib/wwise"
 	"github.com/mircearoata/wwise-cli/lib/wwise/client"
 	"github.com/mircearoata/wwise-cli/lib/wwise/product"
@@ -18,14 +27,76 @@ import (
 )
 
 type Info struct {
-	Location string
-	Git      *GitInfo
+	Location string   `json:"location"`
+	Git      *GitInfo `json:"git"`
 }
 
 type GitInfo struct {
-	Branch   string
-	Commit   string
-	UpToDate bool
+	Branch   string `json:"branch"`
+	Commit   string `json:"commit"`
+	UpToDate bool   `json:"upToDate"`
+}
+
+// Detect inspects the project in targetPath. Returns nil if there is no project there
+func Detect(targetPath string) (*Info, error) {
+	uprojectPath := TargetPathToUProjectPath(targetPath, true)
+	exists, err := projectExists(uprojectPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not check if the project exists")
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	info := &Info{
+		Location: filepath.Dir(uprojectPath),
+	}
+
+	gitInfo, err := detectGit(info.Location)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not inspect the project repository")
+	}
+	info.Git = gitInfo
+
+	return info, nil
+}
+
+func detectGit(projectDir string) (*GitInfo, error) {
+	repo, err := git.PlainOpen(projectDir)
+	if err == git.ErrRepositoryNotExists {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open the repository")
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get HEAD")
+	}
+
+	info := &GitInfo{
+		Branch: head.Name().Short(),
+		Commit: head.Hash().String(),
+	}
+
+	// Not being able to reach the remote is not an error, the project just can't be known to be up to date
+	remote, err := repo.Remote(git.DefaultRemoteName)
+	if err != nil {
+		return info, nil
+	}
+	refs, err := remote.List(&git.ListOptions{})
+	if err != nil {
+		return info, nil
+	}
+	for _, ref := range refs {
+		if ref.Name() == head.Name() {
+			info.UpToDate = ref.Hash() == head.Hash()
+			break
+		}
+	}
+
+	return info, nil
 }
 
 func projectExists(targetPath string) (bool, error) {
@@ -39,24 +110,52 @@ func projectExists(targetPath string) (bool, error) {
 	return true, nil
 }
 
-func Clone(targetPath string) error {
+const starterProjectURL = "https://github.com/SatisfactoryModding/SatisfactoryModLoader"
+
+// Clone clones the starter project if the target does not exist yet. Nothing is changed if p is not nil, it is filled instead
+func Clone(targetPath string, p *plan.Plan) error {
 	exists, err := projectExists(targetPath)
 	if err != nil {
 		return errors.Wrap(err, "could not check if the project already exists")
 	}
 	if exists {
 		cfmt.Sequence.Printf("Project already exists in '%s', skipping clone\n", targetPath)
+		p.Note("Project already exists in '%s', it will not be cloned", targetPath)
+		return nil
+	} else if p.DryRun() {
+		destination := filepath.Join(targetPath, "SatisfactoryModLoader")
+		p.Download("SatisfactoryModLoader (git clone)", starterProjectURL, -1, destination)
+		p.Directory(destination)
 		return nil
 	} else {
 		cfmt.Sequence.Printf("Cloning starter project to '%s' (this can take many minutes)...\n", targetPath)
+		phases := progress.NewPhases("Clone")
 		_, err := git.PlainClone(filepath.Join(targetPath, "SatisfactoryModLoader"), false, &git.CloneOptions{
-			URL:      "https://github.com/SatisfactoryModding/SatisfactoryModLoader",
-			Progress: os.Stdout,
+			URL:      starterProjectURL,
+			Progress: phases,
 		})
-		return err
+		if err != nil {
+			phases.Fail()
+			return err
+		}
+		phases.Done()
+		return recordClone(targetPath)
 	}
 }
 
+// recordClone remembers that SMEI cloned the project in targetPath, so that 'smei uninstall' may remove it
+func recordClone(targetPath string) error {
+	location, err := filepath.Abs(filepath.Join(targetPath, "SatisfactoryModLoader"))
+	if err != nil {
+		return errors.Wrap(err, "could not make the project path absolute")
+	}
+	err = state.Update(func(s *state.State) {
+		s.RemoveProject(location)
+		s.Projects = append(s.Projects, state.Install{Location: location, Installed: time.Now()})
+	})
+	return errors.Wrap(err, "could not record the clone")
+}
+
 func TargetPathToUProjectPath(targetPath string, useSmlMiddle bool) string {
 	if useSmlMiddle {
 		return filepath.Join(targetPath, "SatisfactoryModLoader", "FactoryGame.uproject")
@@ -75,13 +174,19 @@ func makeUBTArguments(targetPath string) []string {
 	}
 }
 
-func GenerateProjectFiles(targetPath, UEPath string) error {
-	cfmt.Sequence.Println("Generating Visual Studio project files...")
+func GenerateProjectFiles(targetPath, UEPath string, p *plan.Plan) error {
 	UBTPath := filepath.Join(UEPath, "Engine", "Binaries", "DotNET", "UnrealBuildTool.exe")
 	arguments := makeUBTArguments(targetPath)
-	cmd := exec.Command(UBTPath, arguments...)
-	fmt.Println(cmd)
-	err := cmd.Run()
+	if p.DryRun() {
+		p.Command(UBTPath, arguments...)
+		return nil
+	}
+
+	cfmt.Sequence.Println("Generating Visual Studio project files...")
+	err := progress.Run("Project files", func(w io.Writer) error {
+		_, err := command.Run(context.Background(), command.Command{Name: UBTPath, Args: arguments, Stdout: w, Stderr: w})
+		return err
+	})
 	if err != nil {
 		return fmt.Errorf("generation command failed: %v", err)
 	}
@@ -89,35 +194,38 @@ func GenerateProjectFiles(targetPath, UEPath string) error {
 	return nil
 }
 
-func BuildAll(targetPath, UEPath string) error {
+func BuildAll(targetPath, UEPath string, p *plan.Plan) error {
 	cfmt.Sequence.Println("Building Development Editor...")
-	err := BuildDevEditor(targetPath, UEPath)
+	err := BuildDevEditor(targetPath, UEPath, p)
 	if err != nil {
 		return err
 	}
 	cfmt.Sequence.Println("Building Shipping...")
-	err = BuildShipping(targetPath, UEPath)
+	err = BuildShipping(targetPath, UEPath, p)
 	// TODO build dedicated servers
 	return err
 }
 
-func BuildShipping(targetPath, UEPath string) error {
-	return Build(targetPath, UEPath, true)
+func BuildShipping(targetPath, UEPath string, p *plan.Plan) error {
+	return Build(targetPath, UEPath, true, p)
 }
 
-func BuildDevEditor(targetPath, UEPath string) error {
-	return Build(targetPath, UEPath, false)
+func BuildDevEditor(targetPath, UEPath string, p *plan.Plan) error {
+	return Build(targetPath, UEPath, false, p)
 }
 
-func Build(targetPath, UEPath string, shipping bool) error {
+func Build(targetPath, UEPath string, shipping bool, p *plan.Plan) error {
 	buildScript := filepath.Join(UEPath, "Engine", "Build", "BatchFiles", "Build.bat")
 	arguments := makeBuildArguments(targetPath, shipping)
-	fmt.Println(buildScript, arguments)
-	cmd := exec.Command(buildScript, arguments...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	err := cmd.Run()
-	return err
+	if p.DryRun() {
+		p.Directory(filepath.Join(targetPath, "SatisfactoryModLoader", "Binaries"))
+		p.Command(buildScript, arguments...)
+		return nil
+	}
+	return progress.Run("Build", func(w io.Writer) error {
+		_, err := command.Run(context.Background(), command.Command{Name: buildScript, Args: arguments, Stdout: w, Stderr: w})
+		return err
+	})
 }
 
 func makeBuildArguments(targetPath string, shipping bool) []string {
@@ -135,35 +243,35 @@ func makeTargetArguments(shipping bool) []string {
 	return []string{"FactoryGameEditor", "Win64", "Development"}
 }
 
-func Install(targetPath string, UEPath string, auth credentials.WwiseAuth) error {
-	var err error
-	err = Clone(targetPath)
-	if err != nil {
-		return fmt.Errorf("could not clone the project: %v", err)
-	}
+func IsCloned(targetPath string) (bool, error) {
+	return projectExists(TargetPathToUProjectPath(targetPath, true))
+}
 
-	err = InstallWWise(targetPath, auth)
-	if err != nil {
-		return errors.Wrap(err, "could not move the Wwise install")
-	}
+func HasWwise(targetPath string) (bool, error) {
+	return projectExists(filepath.Join(targetPath, "SatisfactoryModLoader", "Plugins", "Wwise"))
+}
 
-	err = GenerateProjectFiles(targetPath, UEPath)
-	if err != nil {
-		return fmt.Errorf("could not generate the VS project files: %v", err)
-	}
+func HasProjectFiles(targetPath string) (bool, error) {
+	return projectExists(filepath.Join(targetPath, "SatisfactoryModLoader", "FactoryGame.sln"))
+}
 
-	err = BuildAll(targetPath, UEPath)
+// IsBuilt checks for the binaries produced by Build
+func IsBuilt(targetPath string, shipping bool) (bool, error) {
+	pattern := "UE4Editor-FactoryGame*.dll"
+	if shipping {
+		pattern = "FactoryGame-Win64-Shipping*"
+	}
+	matches, err := filepath.Glob(filepath.Join(targetPath, "SatisfactoryModLoader", "Binaries", "Win64", pattern))
 	if err != nil {
-		return fmt.Errorf("could not build the project: %v", err)
+		return false, errors.Wrap(err, "could not search for the binaries")
 	}
-
-	return nil
+	return len(matches) > 0, nil
 }
 
-func InstallWWise(uprojectPath string, auth credentials.WwiseAuth) error {
+// InstallWWise downloads the Wwise SDK and integrates Wwise into the project. Nothing is changed if p is not nil, it is filled instead
+func InstallWWise(uprojectPath string, auth credentials.WwiseAuth, p *plan.Plan) error {
 	sdkVersion := viper.GetString(config.WwiseSdkVersion_key)
 	integrationVersion := viper.GetString(config.WwiseIntegrationVersion_key)
-	cfmt.Sequence.Printf("Downloading Wwise sdk %s files...\n", sdkVersion)
 	wwiseClient := client.NewWwiseClient()
 
 	err := wwiseClient.Authenticate(string(auth.Email), string(auth.Password))
@@ -171,7 +279,17 @@ func InstallWWise(uprojectPath string, auth credentials.WwiseAuth) error {
 		return errors.Wrap(err, "authentication error. check your Wwise credentials")
 	}
 
+	err = validateWwiseVersions(wwiseClient, sdkVersion, integrationVersion, p)
+	if err != nil {
+		return err
+	}
+
 	sdk := product.NewWwiseProduct(wwiseClient, "wwise")
+	if p.DryRun() {
+		return planWwise(uprojectPath, sdk, sdkVersion, integrationVersion, p)
+	}
+
+	cfmt.Sequence.Printf("Downloading Wwise sdk %s files...\n", sdkVersion)
 	sdkProductVersion, err := sdk.GetVersion(sdkVersion)
 	if err != nil {
 		return errors.Wrap(err, "could not get SDK version")
@@ -182,24 +300,144 @@ func InstallWWise(uprojectPath string, auth credentials.WwiseAuth) error {
 		return errors.Wrap(err, "could not get SDK version info")
 	}
 
-	files := sdkVersionInfo.FindFilesByGroups([]product.GroupFilter{
-		{GroupID: "Packages", GroupValues: []string{"SDK"}},
-		{GroupID: "DeploymentPlatforms", GroupValues: []string{"Windows_vc140", "Windows_vc150", "Windows_vc160", "Mac", "Linux", ""}},
+	err = downloadSdkFiles(sdkProductVersion.Dir, findSdkFiles(sdkVersionInfo))
+	if err != nil {
+		return errors.Wrap(err, "could not download the SDK files")
+	}
+
+	cfmt.Sequence.Printf("Integrating Wwise %s files...\n", integrationVersion)
+	phases := progress.NewPhases("Wwise integration")
+	fmt.Fprintln(phases, "downloading the Unreal integration")
+	err = downloadIntegration(uprojectPath, integrationVersion, wwiseClient)
+	if err != nil {
+		phases.Fail()
+		return errors.Wrap(err, "could not download the Unreal integration")
+	}
+	fmt.Fprintln(phases, "copying the integration into the project")
+	err = wwise.IntegrateWwiseUnreal(uprojectPath, integrationVersion, wwiseClient)
+	if err != nil {
+		phases.Fail()
+		return errors.Wrap(err, "integration failed")
+	}
+	phases.Done()
+
+	return nil
+}
+
+// downloadIntegration downloads the integration files for the engine of the project the way wwise.IntegrateWwiseUnreal does,
+// which then finds them cached. This separates the download from the copy in the progress
+func downloadIntegration(uprojectPath, integrationVersion string, wwiseClient *client.WwiseClient) error {
+	version, err := product.NewWwiseProduct(wwiseClient, "unrealintegration").GetVersion(integrationVersion)
+	if err != nil {
+		return errors.Wrap(err, "could not get the integration version")
+	}
+	info, err := version.GetInfo()
+	if err != nil {
+		return errors.Wrap(err, "could not get the integration version info")
+	}
+
+	engineRoot, err := unrealengine.GetEngineRootFromProject(uprojectPath)
+	if err != nil {
+		return errors.Wrap(err, "could not get the engine of the project")
+	}
+	engine, err := unrealengine.GetEngineVersionData(engineRoot)
+	if err != nil {
+		return errors.Wrap(err, "could not get the engine version")
+	}
+
+	files := info.FindFilesByGroups([]product.GroupFilter{
+		{GroupID: "DeploymentPlatforms", GroupValues: []string{fmt.Sprintf("UE%d%d", engine.MajorVersion, engine.MinorVersion)}},
 	})
+	if len(files) != 1 {
+		return errors.Errorf("no integration file for Unreal Engine %d.%d", engine.MajorVersion, engine.MinorVersion)
+	}
+	return version.DownloadOrCache(files[0])
+}
 
-	for _, file := range files {
-		fmt.Printf("Downloading %v from %v\n", file.Name, file.URL)
-		err = sdkProductVersion.DownloadOrCache(file)
+// validateWwiseVersions fails early on versions that do not exist, instead of when their files are requested.
+// When the versions cannot be fetched, the cached ones are used but only warned about, as they may be outdated
+func validateWwiseVersions(wwiseClient *client.WwiseClient, sdkVersion, integrationVersion string, p *plan.Plan) error {
+	catalog, fetchErr := wwiseversions.FetchCatalog(wwiseClient)
+	if fetchErr != nil {
+		var err error
+		catalog, err = wwiseversions.LoadCatalog()
 		if err != nil {
-			return errors.Wrapf(err, "could not download file %v", file.Name)
+			return err
+		}
+		if catalog == nil {
+			cfmt.Warning.Printf("Could not get the available Wwise versions, the configured versions are not checked: %v\n", fetchErr)
+			return nil
+		}
+		cfmt.Warning.Printf("Could not get the available Wwise versions, using the ones cached on %v: %v\n", catalog.Fetched.Format("2006-01-02"), fetchErr)
+	} else if !p.DryRun() {
+		err := catalog.Save()
+		if err != nil {
+			return err
 		}
 	}
 
-	cfmt.Sequence.Printf("Integrating Wwise %s files...\n", integrationVersion)
-	err = wwise.IntegrateWwiseUnreal(uprojectPath, integrationVersion, wwiseClient)
+	warnings, err := catalog.Validate(sdkVersion, integrationVersion, "")
+	if err != nil && fetchErr != nil {
+		cfmt.Warning.Printf("%v. The cached versions may be outdated, continuing anyway\n", err)
+		return nil
+	}
 	if err != nil {
-		return errors.Wrap(err, "integration failed")
+		return err
+	}
+	for _, warning := range warnings {
+		cfmt.Warning.Println(warning)
+	}
+	return nil
+}
+
+func findSdkFiles(info product.ProductVersionInfo) []product.File {
+	return info.FindFilesByGroups([]product.GroupFilter{
+		{GroupID: "Packages", GroupValues: []string{"SDK"}},
+		{GroupID: "DeploymentPlatforms", GroupValues: []string{"Windows_vc140", "Windows_vc150", "Windows_vc160", "Mac", "Linux", ""}},
+	})
+}
+
+// planWwiseWithoutCredentials records what is known of the Wwise install when the files cannot be listed without the Audiokinetic credentials
+func planWwiseWithoutCredentials(uprojectPath string, p *plan.Plan) {
+	sdkVersion := viper.GetString(config.WwiseSdkVersion_key)
+	integrationVersion := viper.GetString(config.WwiseIntegrationVersion_key)
+	dir := filepath.Join(viper.GetString(config.WwiseCacheDir_key), "wwise", strings.TrimPrefix(sdkVersion, "wwise."))
+
+	p.Directory(dir)
+	p.Download(fmt.Sprintf("Wwise SDK %v files", sdkVersion), "https://www.audiokinetic.com", -1, dir)
+	p.Directory(filepath.Join(filepath.Dir(uprojectPath), "Plugins", "Wwise"))
+	p.Note("Wwise Unreal integration %v will be downloaded and integrated into '%v'", integrationVersion, uprojectPath)
+}
+
+// planWwise fills p without going through product.WwiseProduct.GetVersion, which creates the cache directory
+func planWwise(uprojectPath string, sdk *product.WwiseProduct, sdkVersion, integrationVersion string, p *plan.Plan) error {
+	sdkVersion = strings.TrimPrefix(sdkVersion, sdk.ProductName+".")
+	sdkProductVersion := &product.WwiseProductVersion{
+		Product:   sdk,
+		VersionId: sdkVersion,
+		Dir:       filepath.Join(viper.GetString(config.WwiseCacheDir_key), sdk.ProductName, sdkVersion),
+	}
+
+	sdkVersionInfo, err := sdkProductVersion.GetInfo()
+	if err != nil {
+		return errors.Wrap(err, "could not get SDK version info")
+	}
+
+	downloaded, err := readDownloadedWwiseFiles(sdkProductVersion.Dir)
+	if err != nil {
+		return errors.Wrap(err, "could not read the downloaded Wwise files")
+	}
+
+	p.Directory(sdkProductVersion.Dir)
+	for _, file := range findSdkFiles(sdkVersionInfo) {
+		if downloaded[file.Name] {
+			p.Note("Wwise file %v is cached", file.Name)
+			continue
+		}
+		p.Download(file.Name, file.URL, int64(file.Size), sdkProductVersion.Dir)
 	}
 
+	p.Directory(filepath.Join(filepath.Dir(uprojectPath), "Plugins", "Wwise"))
+	p.Note("Wwise Unreal integration %v will be downloaded and integrated into '%v'", integrationVersion, uprojectPath)
 	return nil
 }
@@ -0,0 +1,123 @@
+package project
+
+import (
+	"github.com/satisfactorymodding/SMEI/lib/cfmt"
+	"github.com/satisfactorymodding/SMEI/lib/credentials"
+	"github.com/satisfactorymodding/SMEI/lib/pipeline"
+)
+
+// CloneStep clones the starter project into the target
+type CloneStep struct{}
+
+func (CloneStep) Name() string {
+	return "clone"
+}
+
+func (CloneStep) Dependencies() []string {
+	return nil
+}
+
+func (CloneStep) Check(ctx *pipeline.Context) (bool, error) {
+	return IsCloned(ctx.Target)
+}
+
+func (CloneStep) Run(ctx *pipeline.Context) error {
+	cfmt.Sequence.Println("Installing modding project...")
+	return Clone(ctx.Target, ctx.Plan)
+}
+
+// WwiseStep downloads Wwise and integrates it into the project
+type WwiseStep struct{}
+
+func (WwiseStep) Name() string {
+	return "wwise"
+}
+
+func (WwiseStep) Dependencies() []string {
+	return []string{"clone"}
+}
+
+// Prepare collects the Wwise credentials in advance of any downloading steps so no interactivity is required mid-install.
+// Dry runs only use the credentials available without prompting
+func (WwiseStep) Prepare(ctx *pipeline.Context) error {
+	if ctx.Wwise != nil {
+		return nil
+	}
+	if ctx.Plan.DryRun() {
+		auth, err := credentials.AvailableWwiseCredentials()
+		if err != nil {
+			return err
+		}
+		if auth == nil {
+			ctx.Plan.Note("The Audiokinetic credentials would be asked for to download Wwise")
+		}
+		ctx.Wwise = auth
+		return nil
+	}
+	auth, err := credentials.GetWwiseCredentials()
+	if err != nil {
+		return err
+	}
+	ctx.Wwise = auth
+	return nil
+}
+
+func (WwiseStep) Check(ctx *pipeline.Context) (bool, error) {
+	return HasWwise(ctx.Target)
+}
+
+func (WwiseStep) Run(ctx *pipeline.Context) error {
+	if ctx.Wwise == nil {
+		planWwiseWithoutCredentials(TargetPathToUProjectPath(ctx.Target, true), ctx.Plan)
+		return nil
+	}
+	return InstallWWise(TargetPathToUProjectPath(ctx.Target, true), *ctx.Wwise, ctx.Plan)
+}
+
+// ProjectFilesStep generates the Visual Studio project files
+type ProjectFilesStep struct{}
+
+func (ProjectFilesStep) Name() string {
+	return "project-files"
+}
+
+func (ProjectFilesStep) Dependencies() []string {
+	return []string{"ue", "clone", "wwise"}
+}
+
+func (ProjectFilesStep) Check(ctx *pipeline.Context) (bool, error) {
+	return HasProjectFiles(ctx.Target)
+}
+
+func (ProjectFilesStep) Run(ctx *pipeline.Context) error {
+	return GenerateProjectFiles(ctx.Target, ctx.UEInstallDir, ctx.Plan)
+}
+
+// BuildStep builds one target of the project
+type BuildStep struct {
+	Shipping bool
+}
+
+func (s BuildStep) Name() string {
+	if s.Shipping {
+		return "build-shipping"
+	}
+	return "build-editor"
+}
+
+func (BuildStep) Dependencies() []string {
+	return []string{"vs", "project-files"}
+}
+
+func (s BuildStep) Check(ctx *pipeline.Context) (bool, error) {
+	return IsBuilt(ctx.Target, s.Shipping)
+}
+
+func (s BuildStep) Run(ctx *pipeline.Context) error {
+	if s.Shipping {
+		cfmt.Sequence.Println("Building Shipping...")
+	} else {
+		cfmt.Sequence.Println("Building Development Editor...")
+	}
+	return Build(ctx.Target, ctx.UEInstallDir, s.Shipping, ctx.Plan)
+}
@@ -0,0 +1,54 @@
+package project
+
+import (
+	"github.com/satisfactorymodding/SMEI/lib/cfmt"
+	"github.com/satisfactorymodding/SMEI/lib/journal"
+	"github.com/satisfactorymodding/SMEI/lib/report"
+	"github.com/satisfactorymodding/SMEI/lib/state"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// ErrNotClonedBySMEI is returned when removing a project SMEI did not clone
+var ErrNotClonedBySMEI = errors.New("the project was not cloned by SMEI")
+
+// ClonedTargets returns the target of every project SMEI cloned
+func ClonedTargets() ([]string, error) {
+	s, err := state.Load()
+	if err != nil {
+		return nil, err
+	}
+	var r []string
+	for _, project := range s.Projects {
+		r = append(r, filepath.Dir(project.Location))
+	}
+	return r, nil
+}
+
+// Remove deletes the project SMEI cloned in targetPath, along with its install journal
+func Remove(targetPath string) error {
+	location := filepath.Join(targetPath, "SatisfactoryModLoader")
+	s, err := state.Load()
+	if err != nil {
+		return err
+	}
+	if s.FindProject(location) == nil {
+		return report.WithHint(errors.Wrapf(ErrNotClonedBySMEI, "'%v'", location), "Only projects cloned by 'smei install' can be removed")
+	}
+
+	cfmt.Sequence.Printf("Removing the project in '%v'\n", location)
+	err = os.RemoveAll(location)
+	if err != nil {
+		return errors.Wrap(err, "could not remove the project")
+	}
+	err = journal.Delete(targetPath)
+	if err != nil {
+		return err
+	}
+
+	return state.Update(func(s *state.State) {
+		s.RemoveProject(location)
+	})
+}
@@ -0,0 +1,165 @@
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/satisfactorymodding/SMEI/config"
+	"github.com/satisfactorymodding/SMEI/lib/cfmt"
+	"github.com/satisfactorymodding/SMEI/lib/download"
+	"github.com/satisfactorymodding/SMEI/lib/progress"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/mircearoata/wwise-cli/lib/wwise/product"
+	"github.com/mircearoata/wwise-cli/utils"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+// Where archives are downloaded before being extracted, relative to the version directory
+const wwiseDownloadsFolder = "downloads"
+
+// downloadSdkFiles downloads and extracts files concurrently. product.WwiseProductVersion.DownloadOrCache does the same one
+// file at a time, so the record of downloaded files it keeps in info.json is updated here the same way
+func downloadSdkFiles(versionDir string, files []product.File) error {
+	info, err := readDownloadedWwiseInfo(versionDir)
+	if err != nil {
+		return errors.Wrap(err, "could not read the downloaded Wwise files")
+	}
+
+	var missing []product.File
+	var total int64
+	for _, file := range files {
+		if !info.IsFileDownloaded(file.Name) {
+			missing = append(missing, file)
+			total += int64(file.Size)
+		}
+	}
+	if len(missing) == 0 {
+		cfmt.Sequence.Println("The Wwise SDK files are cached")
+		return nil
+	}
+
+	cfmt.Sequence.Printf("Downloading %v Wwise SDK files\n", len(missing))
+	bar := progress.NewBar("Wwise SDK", total)
+	var mu sync.Mutex
+	jobs := make([]download.Job, len(missing))
+	for i, file := range missing {
+		file := file
+		part := bar.Part()
+		jobs[i] = func(ctx context.Context) error {
+			err := downloadSdkFile(ctx, versionDir, file, part)
+			if err != nil {
+				return errors.Wrapf(err, "could not download file %v", file.Name)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			recordDownloadedWwiseFile(info, file)
+			return saveDownloadedWwiseInfo(versionDir, info)
+		}
+	}
+
+	err = download.Parallel(context.Background(), viper.GetInt(config.DownloadConcurrency_key), jobs)
+	if err != nil {
+		bar.Fail()
+		return err
+	}
+	bar.Done()
+	return nil
+}
+
+func downloadSdkFile(ctx context.Context, versionDir string, file product.File, onProgress func(done int64)) error {
+	archive := filepath.Join(versionDir, wwiseDownloadsFolder, file.Name)
+	req := download.Request{
+		URL:      file.URL,
+		Path:     archive,
+		Size:     int64(file.Size),
+		Progress: onProgress,
+	}
+	if file.Sha1 != "" {
+		req.Digest = "sha1:" + file.Sha1
+	}
+	err := download.Download(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(archive)
+	if err != nil {
+		return errors.Wrap(err, "could not open the archive")
+	}
+	err = utils.ExtractTarXz(f, versionDir)
+	f.Close()
+	if err != nil {
+		return errors.Wrap(err, "could not extract the archive")
+	}
+
+	err = os.Remove(archive)
+	if err != nil {
+		return errors.Wrap(err, "could not remove the archive")
+	}
+	return nil
+}
+
+func recordDownloadedWwiseFile(info *product.WwiseVersionDownloadedInfo, file product.File) {
+	info.Files = append(info.Files, file.Name)
+	for _, group := range file.Groups {
+		if !info.IsGroupDownloaded(group.GroupID, group.GroupValueID) {
+			info.Groups = append(info.Groups, group)
+		}
+	}
+}
+
+func readDownloadedWwiseInfo(versionDir string) (*product.WwiseVersionDownloadedInfo, error) {
+	info := &product.WwiseVersionDownloadedInfo{
+		Files:  []string{},
+		Groups: []product.Group{},
+	}
+
+	data, err := os.ReadFile(filepath.Join(versionDir, "info.json"))
+	if os.IsNotExist(err) {
+		return info, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, info)
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+func saveDownloadedWwiseInfo(versionDir string, info *product.WwiseVersionDownloadedInfo) error {
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "could not marshal the downloaded Wwise files")
+	}
+
+	tmp := filepath.Join(versionDir, "info.json.tmp")
+	err = os.WriteFile(tmp, data, 0644)
+	if err != nil {
+		return errors.Wrap(err, "could not write the downloaded Wwise files")
+	}
+	err = os.Rename(tmp, filepath.Join(versionDir, "info.json"))
+	if err != nil {
+		return errors.Wrap(err, "could not replace the downloaded Wwise files")
+	}
+	return nil
+}
+
+func readDownloadedWwiseFiles(versionDir string) (map[string]bool, error) {
+	info, err := readDownloadedWwiseInfo(versionDir)
+	if err != nil {
+		return nil, err
+	}
+
+	r := map[string]bool{}
+	for _, file := range info.Files {
+		r[file] = true
+	}
+	return r, nil
+}
@@ -1,18 +1,43 @@
 package scan
 
 import (
+	"github.com/satisfactorymodding/SMEI/config"
 	"github.com/satisfactorymodding/SMEI/lib/env/project"
 	"github.com/satisfactorymodding/SMEI/lib/env/ue"
 	"github.com/satisfactorymodding/SMEI/lib/env/vs"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
 )
 
 type EnvInfo struct {
-	UE      *ue.Info
-	VS      *vs.Info
-	Project *project.Info
+	UE      *ue.Info      `json:"ue"`
+	VS      *vs.Info      `json:"vs"`
+	Project *project.Info `json:"project"`
 }
 
-func Scan() (EnvInfo, error) {
+// Scan detects the components of the modding environment. Components that could not be found are left nil.
+// The project is only looked for if target is not empty
+func Scan(target string) (EnvInfo, error) {
 	var info EnvInfo
+	var err error
+
+	info.UE, err = ue.Detect(viper.GetString(config.UEInstallPath_key))
+	if err != nil {
+		return info, errors.Wrap(err, "could not detect the Unreal Engine")
+	}
+
+	info.VS, err = vs.Detect()
+	if err != nil {
+		return info, errors.Wrap(err, "could not detect Visual Studio")
+	}
+
+	if target != "" {
+		info.Project, err = project.Detect(target)
+		if err != nil {
+			return info, errors.Wrap(err, "could not detect the modding project")
+		}
+	}
+
 	return info, nil
 }
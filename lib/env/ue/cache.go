@@ -0,0 +1,246 @@
+package ue
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/satisfactorymodding/SMEI/config"
+	"github.com/satisfactorymodding/SMEI/lib/cfmt"
+	"github.com/satisfactorymodding/SMEI/lib/download"
+	"github.com/satisfactorymodding/SMEI/lib/env/gh"
+	"github.com/satisfactorymodding/SMEI/lib/plan"
+	"github.com/satisfactorymodding/SMEI/lib/progress"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/go-github/v42/github"
+	"github.com/pkg/errors"
+)
+
+// Describes the cached installer, so that it can be verified and compared with the available releases
+const cacheInfoFile = "release.json"
+
+type cacheInfo struct {
+	Tag        string        `json:"tag"`
+	Assets     []cachedAsset `json:"assets"`
+	Downloaded time.Time     `json:"downloaded"`
+}
+
+type cachedAsset struct {
+	ID     int64  `json:"id"`
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	Digest string `json:"digest"`
+}
+
+// CachedReleaseTag returns the release of the cached installer. Empty if unknown
+func CachedReleaseTag() (string, error) {
+	info, err := readCacheInfo(filepath.Dir(getInstallerPath()))
+	if err != nil {
+		return "", err
+	}
+	return info.Tag, nil
+}
+
+// cacheIsUsable checks that the cached installer is complete, intact and of the wanted release.
+// Incomplete caches are downloaded again, which only fetches the missing files
+func cacheIsUsable(p *plan.Plan) (bool, error) {
+	dir := filepath.Dir(getInstallerPath())
+	info, err := readCacheInfo(dir)
+	if err != nil {
+		return false, err
+	}
+	if len(info.Assets) == 0 {
+		_, err = os.Stat(getInstallerPath())
+		if err == nil {
+			cfmt.Sequence.Println("The cached UE installer has no release info, it will be downloaded again")
+		}
+		return false, nil
+	}
+	if info.Downloaded.IsZero() {
+		cfmt.Sequence.Printf("The download of release %v was interrupted, it will be resumed\n", info.Tag)
+		return false, nil
+	}
+
+	intact := false
+	err = progress.Run("Verifying the cached UE installer", func(io.Writer) error {
+		intact, err = verifyCache(dir, info, p)
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+	if !intact {
+		cfmt.Warning.Println("The cached UE installer is incomplete or corrupt")
+		return false, nil
+	}
+
+	pinned := ReleaseTag()
+	if pinned != "" {
+		if info.Tag != pinned {
+			cfmt.Sequence.Printf("The cached UE installer is release %v, not the pinned release %v\n", info.Tag, pinned)
+			return false, nil
+		}
+		return true, nil
+	}
+
+	latest, assets, err := latestReleaseIfOnline()
+	if err != nil {
+		cfmt.Warning.Printf("Could not check for a newer Unreal Engine release, using the cached release %v: %v\n", info.Tag, err)
+		p.Note("Could not check for a newer Unreal Engine release: %v", err)
+		return true, nil
+	}
+	if latest.GetTagName() != info.Tag {
+		cfmt.Sequence.Printf("Unreal Engine release %v is available, the cached installer is release %v. "+
+			"Set %v to keep using the cached release\n", latest.GetTagName(), info.Tag, config.UEReleaseTag_key)
+		return false, nil
+	}
+	if !sameAssets(info.Assets, assets) {
+		cfmt.Sequence.Printf("The files of release %v changed since they were cached\n", info.Tag)
+		return false, nil
+	}
+
+	cfmt.Sequence.Printf("The cached UE installer is the latest release, %v\n", info.Tag)
+	return true, nil
+}
+
+// verifyCache checks the size and digest of every cached file. Invalid files are removed so that they are downloaded again
+func verifyCache(dir string, info cacheInfo, p *plan.Plan) (bool, error) {
+	intact := true
+	for _, asset := range info.Assets {
+		path := filepath.Join(dir, asset.Name)
+		err := download.Verify(path, asset.Size, asset.Digest)
+		if err == nil {
+			continue
+		}
+
+		intact = false
+		if p.DryRun() {
+			p.Note("Cached file '%v' is invalid and will be downloaded again: %v", path, err)
+			continue
+		}
+		err = os.Remove(path)
+		if err != nil && !os.IsNotExist(err) {
+			return false, errors.Wrapf(err, "could not remove invalid file '%v'", path)
+		}
+	}
+	return intact, nil
+}
+
+// latestReleaseIfOnline gets the latest release and its installer files without logging in to GitHub, as the cache can be used without it
+func latestReleaseIfOnline() (*github.RepositoryRelease, []*github.ReleaseAsset, error) {
+	token, err := gh.StoredToken()
+	if err != nil {
+		return nil, nil, err
+	}
+	if token == "" {
+		return nil, nil, errors.New("not logged in to GitHub")
+	}
+
+	ctx := context.Background()
+	client := gh.NewClient(ctx, string(token))
+	release, err := LatestRelease(ctx, client)
+	if err != nil {
+		return nil, nil, err
+	}
+	assets, err := getAssetsToDownload(ctx, client, release)
+	if err != nil {
+		return nil, nil, err
+	}
+	return release, assets, nil
+}
+
+func sameAssets(cached []cachedAsset, assets []*github.ReleaseAsset) bool {
+	if len(cached) != len(assets) {
+		return false
+	}
+	ids := map[int64]bool{}
+	for _, asset := range cached {
+		ids[asset.ID] = true
+	}
+	for _, asset := range assets {
+		if !ids[asset.GetID()] {
+			return false
+		}
+	}
+	return true
+}
+
+func readCacheInfo(dir string) (cacheInfo, error) {
+	var info cacheInfo
+	data, err := os.ReadFile(filepath.Join(dir, cacheInfoFile))
+	if os.IsNotExist(err) {
+		return info, nil
+	}
+	if err != nil {
+		return info, errors.Wrap(err, "could not read the cached release info")
+	}
+
+	err = json.Unmarshal(data, &info)
+	if err != nil {
+		return info, errors.Wrap(err, "could not parse the cached release info")
+	}
+	return info, nil
+}
+
+// prepareCacheDir removes the cached files of any other release, as the files of every release have the same names.
+// The release about to be downloaded is recorded first, so that an interrupted download is resumed instead of removed
+func prepareCacheDir(dir string, release *github.RepositoryRelease, assets []*github.ReleaseAsset) error {
+	info, err := readCacheInfo(dir)
+	if err != nil {
+		return err
+	}
+
+	if info.Tag != release.GetTagName() || !sameAssets(info.Assets, assets) {
+		files, err := os.ReadDir(dir)
+		if err != nil {
+			return errors.Wrap(err, "could not list the cached files")
+		}
+		for _, file := range files {
+			err = os.RemoveAll(filepath.Join(dir, file.Name()))
+			if err != nil {
+				return errors.Wrapf(err, "could not remove '%v'", file.Name())
+			}
+		}
+	}
+
+	pending := cacheInfo{Tag: release.GetTagName()}
+	for _, asset := range assets {
+		pending.Assets = append(pending.Assets, cachedAsset{
+			ID:   asset.GetID(),
+			Name: asset.GetName(),
+			Size: int64(asset.GetSize()),
+		})
+	}
+	return saveCacheInfo(dir, pending)
+}
+
+// writeCacheInfo records the downloaded release, with the digests of its files
+func writeCacheInfo(dir string, release *github.RepositoryRelease, assets []*github.ReleaseAsset) error {
+	info := cacheInfo{
+		Tag:        release.GetTagName(),
+		Downloaded: time.Now(),
+	}
+	for _, asset := range assets {
+		digest, err := download.Digest(filepath.Join(dir, asset.GetName()))
+		if err != nil {
+			return errors.Wrapf(err, "could not get the digest of '%v'", asset.GetName())
+		}
+		info.Assets = append(info.Assets, cachedAsset{
+			ID:     asset.GetID(),
+			Name:   asset.GetName(),
+			Size:   int64(asset.GetSize()),
+			Digest: digest,
+		})
+	}
+	return saveCacheInfo(dir, info)
+}
+
+func saveCacheInfo(dir string, info cacheInfo) error {
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, cacheInfoFile), data, 0644)
+}
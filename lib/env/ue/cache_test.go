@@ -0,0 +1,88 @@
+package ue
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-github/v42/github"
+)
+
+func testRelease(tag string, ids ...int64) (*github.RepositoryRelease, []*github.ReleaseAsset) {
+	var assets []*github.ReleaseAsset
+	for i, id := range ids {
+		assets = append(assets, &github.ReleaseAsset{
+			ID:   github.Int64(id),
+			Name: github.String([]string{installerName, "UnrealEngine-CSS-Editor-Win64-1.bin"}[i]),
+			Size: github.Int(4),
+		})
+	}
+	return &github.RepositoryRelease{TagName: github.String(tag)}, assets
+}
+
+func TestPrepareCacheDir(t *testing.T) {
+	tests := []struct {
+		name      string
+		cachedTag string
+		cachedIDs []int64
+		tag       string
+		ids       []int64
+		kept      bool
+	}{
+		{name: "same release", cachedTag: "v1", cachedIDs: []int64{1, 2}, tag: "v1", ids: []int64{1, 2}, kept: true},
+		{name: "newer release", cachedTag: "v1", cachedIDs: []int64{1, 2}, tag: "v2", ids: []int64{3, 4}},
+		{name: "files replaced", cachedTag: "v1", cachedIDs: []int64{1, 2}, tag: "v1", ids: []int64{1, 5}},
+		{name: "unknown release", tag: "v1", ids: []int64{1, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.cachedTag != "" {
+				cached, assets := testRelease(tt.cachedTag, tt.cachedIDs...)
+				for _, asset := range assets {
+					err := os.WriteFile(filepath.Join(dir, asset.GetName()), []byte("old!"), 0644)
+					if err != nil {
+						t.Fatal(err)
+					}
+				}
+				err := writeCacheInfo(dir, cached, assets)
+				if err != nil {
+					t.Fatal(err)
+				}
+			} else {
+				err := os.WriteFile(filepath.Join(dir, installerName), []byte("old!"), 0644)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			err := os.WriteFile(filepath.Join(dir, "UnrealEngine-CSS-Editor-Win64-1.bin.part"), []byte("ol"), 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			wanted, assets := testRelease(tt.tag, tt.ids...)
+			err = prepareCacheDir(dir, wanted, assets)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, name := range []string{installerName, "UnrealEngine-CSS-Editor-Win64-1.bin.part"} {
+				_, err = os.Stat(filepath.Join(dir, name))
+				if kept := err == nil; kept != tt.kept {
+					t.Errorf("'%v' kept = %v, want %v", name, kept, tt.kept)
+				}
+			}
+
+			info, err := readCacheInfo(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Tag != tt.tag || !sameAssets(info.Assets, assets) {
+				t.Errorf("recorded release %v %+v, want %v", info.Tag, info.Assets, tt.tag)
+			}
+			if !info.Downloaded.IsZero() {
+				t.Error("the pending release is recorded as downloaded")
+			}
+		})
+	}
+}
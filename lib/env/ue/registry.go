@@ -0,0 +1,189 @@
+package ue
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/satisfactorymodding/SMEI/lib/cfmt"
+	"github.com/satisfactorymodding/SMEI/lib/registry"
+	"github.com/satisfactorymodding/SMEI/lib/state"
+	"path/filepath"
+)
+
+const ueInstallerID = "F9524AE1-57B8-4EB8-BC4D-951EF9656DC7"
+
+var infoPath = fmt.Sprintf(`Software\Microsoft\Windows\CurrentVersion\Uninstall\{%v}_is1`, ueInstallerID)
+
+// Registry is where the installer's entry is read and changed. Replaced by a fake in tests
+var Registry registry.Registry = registry.System()
+
+func isReinstall(installPath string) (bool, error) {
+	key, _, err := openSetupKey(false)
+	if errors.Is(err, registry.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrap(err, "could not open the UE setup registry key")
+	}
+	defer key.Close()
+
+	current, err := key.GetStringValue("InstallLocation")
+	if err != nil {
+		return false, errors.Wrap(err, "could not get the current install location")
+	}
+	installPath, err = filepath.Abs(installPath)
+	if err != nil {
+		return false, errors.Wrap(err, "could not make install path absolute")
+	}
+	current, err = filepath.Abs(current)
+	if err != nil {
+		return false, errors.Wrap(err, "could not make current path absolute")
+	}
+
+	return current == installPath, nil
+}
+
+func getRegisteredInstallLocation() (string, error) {
+	key, _, err := openSetupKey(false)
+	if errors.Is(err, registry.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "could not open the UE setup registry key")
+	}
+	defer key.Close()
+
+	location, err := key.GetStringValue("InstallLocation")
+	if err != nil {
+		return "", errors.Wrap(err, "could not get the current install location")
+	}
+	return location, nil
+}
+
+func hasOtherInstall() (bool, error) {
+	key, _, err := openSetupKey(false)
+	if errors.Is(err, registry.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrap(err, "could not open the UE setup registry key")
+	}
+	key.Close()
+	return true, nil
+}
+
+// openSetupKey opens the installer's entry for the current user, then for all users
+func openSetupKey(write bool) (registry.Key, registry.Root, error) {
+	var err error
+	for _, root := range registry.Roots {
+		var key registry.Key
+		key, err = Registry.OpenKey(root, infoPath, write)
+		if err == nil {
+			return key, root, nil
+		}
+		if !errors.Is(err, registry.ErrNotExist) {
+			return nil, "", err
+		}
+	}
+	return nil, "", err
+}
+
+func disableUninstaller() error {
+	key, _, err := openSetupKey(true)
+	if err != nil {
+		return errors.Wrap(err, "could not open the UE setup registry key")
+	}
+	defer key.Close()
+
+	err = key.SetStringValue("UninstallString", "")
+	if err != nil {
+		return errors.Wrap(err, "could not empty the uninstall string")
+	}
+
+	return nil
+}
+
+func getUninstallString() (string, error) {
+	key, _, err := openSetupKey(false)
+	if err != nil {
+		return "", errors.Wrap(err, "could not open the UE setup registry key")
+	}
+	defer key.Close()
+
+	uninstallString, err := key.GetStringValue("UninstallString")
+	if err != nil {
+		return "", errors.Wrap(err, "could not get the registry value")
+	}
+	return uninstallString, nil
+}
+
+func reenableUninstall(uninstallString string) error {
+	cfmt.Sequence.Println("Reenabling uninstaller")
+	key, _, err := openSetupKey(true)
+	if err != nil {
+		return errors.Wrap(err, "could not open the UE setup registry key")
+	}
+	defer key.Close()
+
+	err = key.SetStringValue("UninstallString", uninstallString)
+	if err != nil {
+		return errors.Wrap(err, "could not empty the uninstall string")
+	}
+	return nil
+}
+
+// readUninstallEntry returns the current uninstall entry, nil if there is none
+func readUninstallEntry() (*state.UninstallEntry, error) {
+	key, root, err := openSetupKey(false)
+	if errors.Is(err, registry.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open the UE setup registry key")
+	}
+	defer key.Close()
+
+	entry := &state.UninstallEntry{Root: string(root)}
+	for name, value := range map[string]*string{
+		"DisplayName":     &entry.DisplayName,
+		"InstallLocation": &entry.InstallLocation,
+		"UninstallString": &entry.UninstallString,
+	} {
+		*value, err = key.GetStringValue(name)
+		if err != nil && !errors.Is(err, registry.ErrNotExist) {
+			return nil, errors.Wrapf(err, "could not get the %v registry value", name)
+		}
+	}
+	return entry, nil
+}
+
+// restoreUninstallEntry puts back an uninstall entry SMEI replaced, creating the key again if the entry was removed since
+func restoreUninstallEntry(entry state.UninstallEntry) error {
+	current, err := readUninstallEntry()
+	if err != nil {
+		return err
+	}
+	if current != nil && state.SamePath(current.InstallLocation, entry.InstallLocation) {
+		return reenableUninstall(entry.UninstallString)
+	}
+	if current != nil {
+		return errors.Errorf("the uninstall entry now belongs to the install in '%v'", current.InstallLocation)
+	}
+
+	key, err := Registry.CreateKey(registry.Root(entry.Root), infoPath)
+	if err != nil {
+		return errors.Wrap(err, "could not create the UE setup registry key")
+	}
+	defer key.Close()
+
+	for name, value := range map[string]string{
+		"DisplayName":     entry.DisplayName,
+		"InstallLocation": entry.InstallLocation,
+		"UninstallString": entry.UninstallString,
+	} {
+		err = key.SetStringValue(name, value)
+		if err != nil {
+			return errors.Wrapf(err, "could not set the %v registry value", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package ue
+
+import (
+	"context"
+	"fmt"
+	"github.com/satisfactorymodding/SMEI/config"
+	"github.com/satisfactorymodding/SMEI/lib/report"
+	"net/http"
+
+	"github.com/google/go-github/v42/github"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+// ReleaseTagOverride replaces the pinned release tag for this run only, without changing the config
+var ReleaseTagOverride string
+
+// ReleaseTag returns the release tag to install, empty to use the latest release
+func ReleaseTag() string {
+	if ReleaseTagOverride != "" {
+		return ReleaseTagOverride
+	}
+	return viper.GetString(config.UEReleaseTag_key)
+}
+
+// getRelease returns the release with tag, or the latest release if tag is empty
+func getRelease(ctx context.Context, client *github.Client, tag string) (*github.RepositoryRelease, error) {
+	if tag == "" {
+		release, _, err := client.Repositories.GetLatestRelease(ctx, orgName, repoName)
+		if err != nil {
+			return nil, fmt.Errorf("could not get the latest release: %v", err)
+		}
+		return release, nil
+	}
+
+	release, resp, err := client.Repositories.GetReleaseByTag(ctx, orgName, repoName, tag)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return nil, report.WithHint(fmt.Errorf("there is no Unreal Engine release '%v'", tag),
+			"Run 'smei ue releases' to list the available releases")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not get release '%v': %v", tag, err)
+	}
+	return release, nil
+}
+
+// assetDigests gets the "sha256:<hex>" digests GitHub computes for the assets of a release, by asset ID.
+// go-github does not decode them yet. Assets uploaded before GitHub computed digests have none
+func assetDigests(ctx context.Context, client *github.Client, release *github.RepositoryRelease) (map[int64]string, error) {
+	req, err := client.NewRequest(http.MethodGet, fmt.Sprintf("repos/%v/%v/releases/%v/assets?per_page=100", orgName, repoName, release.GetID()), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not make the request")
+	}
+	var assets []struct {
+		ID     int64  `json:"id"`
+		Digest string `json:"digest"`
+	}
+	_, err = client.Do(ctx, req, &assets)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get the assets of release %v", release.GetTagName())
+	}
+
+	digests := map[int64]string{}
+	for _, asset := range assets {
+		if asset.Digest != "" {
+			digests[asset.ID] = asset.Digest
+		}
+	}
+	return digests, nil
+}
+
+func LatestRelease(ctx context.Context, client *github.Client) (*github.RepositoryRelease, error) {
+	return getRelease(ctx, client, "")
+}
+
+// Releases lists the releases of the engine, newest first
+func Releases(ctx context.Context, client *github.Client) ([]*github.RepositoryRelease, error) {
+	var r []*github.RepositoryRelease
+	opt := &github.ListOptions{PerPage: 100}
+	for {
+		releases, resp, err := client.Repositories.ListReleases(ctx, orgName, repoName, opt)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not list the releases")
+		}
+		r = append(r, releases...)
+		if resp.NextPage == 0 {
+			return r, nil
+		}
+		opt.Page = resp.NextPage
+	}
+}
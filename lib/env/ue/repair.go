@@ -0,0 +1,60 @@
+package ue
+
+import (
+	"github.com/satisfactorymodding/SMEI/lib/report"
+	"github.com/satisfactorymodding/SMEI/lib/state"
+
+	"github.com/pkg/errors"
+)
+
+// ErrNothingToRepair is returned when SMEI did not replace an uninstall entry
+var ErrNothingToRepair = errors.New("SMEI did not replace the uninstaller of another Unreal Engine install")
+
+// saveReplacedUninstaller persists the current uninstall entry before SMEI disables it.
+// An entry saved by an earlier interrupted install is kept, the registry then only holds the disabled value
+func saveReplacedUninstaller() error {
+	entry, err := readUninstallEntry()
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		return nil
+	}
+	return state.Update(func(s *state.State) {
+		saved := s.ReplacedUninstaller
+		if saved != nil && state.SamePath(saved.InstallLocation, entry.InstallLocation) && entry.UninstallString == "" {
+			return
+		}
+		s.ReplacedUninstaller = entry
+	})
+}
+
+// RepairUninstaller restores the uninstall entry SMEI replaced, so that the other install can be uninstalled from Windows again
+func RepairUninstaller() error {
+	s, err := state.Load()
+	if err != nil {
+		return err
+	}
+	entry := s.ReplacedUninstaller
+	if entry == nil {
+		return ErrNothingToRepair
+	}
+
+	current, err := readUninstallEntry()
+	if err != nil {
+		return err
+	}
+	if current != nil && s.UE != nil && state.SamePath(current.InstallLocation, s.UE.Location) {
+		return report.WithHint(
+			errors.Errorf("the uninstall entry belongs to the Unreal Engine SMEI installed in '%v'", s.UE.Location),
+			"Windows only keeps one entry for both installs. Run 'smei uninstall --ue' to remove SMEI's install, which restores the entry of the install in '"+entry.InstallLocation+"'")
+	}
+
+	err = restoreUninstallEntry(*entry)
+	if err != nil {
+		return err
+	}
+	return state.Update(func(s *state.State) {
+		s.ReplacedUninstaller = nil
+	})
+}
@@ -0,0 +1,31 @@
+package ue
+
+import (
+	"github.com/satisfactorymodding/SMEI/config"
+	"github.com/satisfactorymodding/SMEI/lib/cfmt"
+	"github.com/satisfactorymodding/SMEI/lib/pipeline"
+
+	"github.com/spf13/viper"
+)
+
+// Step installs the Unreal Engine
+type Step struct{}
+
+func (Step) Name() string {
+	return "ue"
+}
+
+func (Step) Dependencies() []string {
+	return nil
+}
+
+func (Step) Check(ctx *pipeline.Context) (bool, error) {
+	info, err := Detect(ctx.UEInstallDir)
+	return info != nil, err
+}
+
+// If lacking github credentials, this will prompt for them. Not needed if the installer files don't need to be downloaded
+func (Step) Run(ctx *pipeline.Context) error {
+	cfmt.Sequence.Println("Analyzing Unreal Engine install")
+	return Install(ctx.UEInstallDir, ctx.InstallerDir, viper.GetBool(config.UESkipReinstall_key), ctx.Plan)
+}
@@ -2,175 +2,313 @@ package ue
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/satisfactorymodding/SMEI/config"
+	"github.com/satisfactorymodding/SMEI/lib/cache"
 	"github.com/satisfactorymodding/SMEI/lib/cfmt"
+	"github.com/satisfactorymodding/SMEI/lib/command"
+	"github.com/satisfactorymodding/SMEI/lib/download"
 	"github.com/satisfactorymodding/SMEI/lib/env/gh"
-	"io/ioutil"
-	"net/http"
+	"github.com/satisfactorymodding/SMEI/lib/plan"
+	"github.com/satisfactorymodding/SMEI/lib/progress"
+	"github.com/satisfactorymodding/SMEI/lib/report"
+	"github.com/satisfactorymodding/SMEI/lib/state"
+	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"regexp"
+	"time"
 
 	"github.com/google/go-github/v42/github"
 	"github.com/pkg/errors"
+	"github.com/spf13/viper"
 )
 
 const orgName = "SatisfactoryModdingUE"
 const repoName = "UnrealEngine"
 const installerName = "UnrealEngine-CSS-Editor-Win64.exe"
-const CacheFolder = "UE-Installer"
+
+// Where the installer was cached before every cache moved to config.CacheDir
+const legacyCacheFolder = "UE-Installer"
 
 type Info struct {
-	Version  string
-	Location string
+	Version  string `json:"version"`
+	Location string `json:"location"`
+}
+
+type buildVersion struct {
+	MajorVersion int
+	MinorVersion int
+	PatchVersion int
+	Changelist   int
+	BranchName   string
+}
+
+// Detect looks for an installed CSS Unreal Engine, first through the installer's registry entry then in expectedDir.
+// Returns nil if no install could be found
+func Detect(expectedDir string) (*Info, error) {
+	location, err := getRegisteredInstallLocation()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get the registered install location")
+	}
+	if location == "" {
+		location = expectedDir
+	}
+
+	version, err := readEngineVersion(location)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read the engine version in '%v'", location)
+	}
+
+	return &Info{
+		Version:  version,
+		Location: location,
+	}, nil
+}
+
+func readEngineVersion(installDir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(installDir, "Engine", "Build", "Build.version"))
+	if err != nil {
+		return "", err
+	}
+
+	var version buildVersion
+	err = json.Unmarshal(data, &version)
+	if err != nil {
+		return "", errors.Wrap(err, "could not parse Build.version")
+	}
+
+	r := fmt.Sprintf("%d.%d.%d", version.MajorVersion, version.MinorVersion, version.PatchVersion)
+	if version.BranchName != "" {
+		r += fmt.Sprintf(" (%v)", version.BranchName)
+	}
+	return r, nil
 }
 
-func Install(installDir, installerDir string, avoidUeReinstall bool) error {
-	cached, err := installerIsCached()
+// Install downloads the installer if needed then runs it if required. Nothing is changed if p is not nil, it is filled instead
+func Install(installDir, installerDir string, avoidUeReinstall bool, p *plan.Plan) error {
+	if !p.DryRun() {
+		migrateLegacyCache()
+	}
+
+	usable, err := cacheIsUsable(p)
 	if err != nil {
-		return errors.Wrap(err, "could not check if the installer is cached")
+		return errors.Wrap(err, "could not check the cached installer")
 	}
-	if !cached {
-		cfmt.Sequence.Println("UE installer is not cached, downloading it. This will require GitHub authentication.")
-		err = downloadInstaller(installerDir)
+	if !usable {
+		cfmt.Sequence.Println("Downloading the UE installer. This will require GitHub authentication.")
+		err = downloadInstaller(installerDir, p)
 		if err != nil {
 			return errors.Wrap(err, "could not download the installer")
 		}
 	} else {
-		fmt.Printf("UE installer is cached in '%s'\n", getInstallerPath())
-		fmt.Println("TODO: Need detection for how old the cached UE version is and/or if there is a newer version available")
+		cfmt.Sequence.Printf("UE installer is cached in '%s'\n", getInstallerPath())
+		p.Note("UE installer is cached in '%s', it will not be downloaded", getInstallerPath())
 	}
 
-	err = runInstallerIfRequired(installerDir, installDir, avoidUeReinstall)
+	err = runInstallerIfRequired(installerDir, installDir, avoidUeReinstall, p)
 	if err != nil {
 		return fmt.Errorf("could not run the Unreal Engine installer: %v", err)
 	}
 
+	if !viper.GetBool(config.PreserveUEInstaller_key) {
+		p.Note("%v is not set, the UE installer will be removed from '%v' after the install", config.PreserveUEInstaller_key, installerDir)
+		if !p.DryRun() {
+			err = os.RemoveAll(installerDir)
+			if err != nil {
+				return errors.Wrap(err, "could not remove the UE installer")
+			}
+		}
+	}
+
 	return nil
 }
 
 func getInstallerPath() string {
-	return filepath.Join(config.ConfigDir, CacheFolder, installerName)
+	return filepath.Join(cache.Dir(cache.UE), installerName)
 }
 
-func installerIsCached() (bool, error) {
-	_, err := os.Stat(getInstallerPath())
-	if os.IsNotExist(err) {
-		return false, nil
+// migrateLegacyCache moves the installer cached by older versions of SMEI, so that it is not downloaded again
+func migrateLegacyCache() {
+	legacy := filepath.Join(config.ConfigDir, legacyCacheFolder)
+	if _, err := os.Stat(legacy); err != nil {
+		return
+	}
+	if _, err := os.Stat(cache.Dir(cache.UE)); err == nil {
+		return
+	}
+
+	err := os.MkdirAll(filepath.Dir(cache.Dir(cache.UE)), 0755)
+	if err == nil {
+		err = os.Rename(legacy, cache.Dir(cache.UE))
 	}
 	if err != nil {
-		return false, err
+		cfmt.Warning.Printf("Could not move the UE installer cached in '%v', it will be downloaded again: %v\n", legacy, err)
+		return
 	}
-	return true, nil
+	cfmt.Sequence.Printf("Moved the UE installer cached in '%v' to '%v'\n", legacy, cache.Dir(cache.UE))
 }
 
-func downloadInstaller(path string) error {
+func downloadInstaller(path string, p *plan.Plan) error {
 	ctx := context.Background()
-	client, err := gh.AuthedClient(ctx)
+	var client *github.Client
+	if p.DryRun() {
+		// Dry runs do not log in, the release is only looked up if a token is already available
+		token, err := gh.AvailableToken()
+		if err != nil {
+			return err
+		}
+		if token == "" {
+			planUnknownRelease(path, p)
+			return nil
+		}
+		client = gh.NewClient(ctx, string(token))
+	} else {
+		var err error
+		client, err = gh.AuthedClient(ctx)
+		if err != nil {
+			return errors.Wrap(err, "error making a GitHub auth client")
+		}
+	}
+
+	err := EnsureGithubAccess(ctx, client)
 	if err != nil {
-		return errors.Wrap(err, "error making a GitHub auth client")
+		return errors.Wrap(err, "could not ensure GitHub access")
 	}
 
-	err = ensureGithubAccess(ctx, client)
+	release, err := getRelease(ctx, client, ReleaseTag())
 	if err != nil {
-		return fmt.Errorf("could not ensure GitHub access: %v", err)
+		return err
 	}
 
-	assetsToDownload, err := getAssetsToDownload(ctx, client)
+	assetsToDownload, err := getAssetsToDownload(ctx, client, release)
 	if err != nil {
 		return fmt.Errorf("could not get the assets to download: %v", err)
 	}
 
-	err = os.MkdirAll(path, 0666)
+	if p.DryRun() {
+		p.Note("Unreal Engine release %v will be downloaded", release.GetTagName())
+		p.Directory(path)
+		for _, asset := range assetsToDownload {
+			p.Download(asset.GetName(), asset.GetBrowserDownloadURL(), int64(asset.GetSize()), path)
+		}
+		return nil
+	}
+
+	err = os.MkdirAll(path, 0755)
 	if err != nil {
 		return fmt.Errorf("could not create the directories for the path '%v': %v", path, err)
 	}
+	err = prepareCacheDir(path, release, assetsToDownload)
+	if err != nil {
+		return errors.Wrap(err, "could not prepare the cache for the download")
+	}
 
+	var total int64
 	for _, asset := range assetsToDownload {
-		err := downloadAsset(ctx, client, asset, path)
-		if err != nil {
-			return fmt.Errorf("could not download asset '%v': %v", asset.GetName(), err)
+		total += int64(asset.GetSize())
+	}
+
+	digests, err := assetDigests(ctx, client, release)
+	if err != nil {
+		cfmt.Warning.Printf("Could not get the checksums of the installer files, only their sizes will be checked: %v\n", err)
+	}
+
+	cfmt.Sequence.Printf("Downloading %v assets\n", len(assetsToDownload))
+	bar := progress.NewBar("UE installer", total)
+	jobs := make([]download.Job, len(assetsToDownload))
+	for i, asset := range assetsToDownload {
+		asset := asset
+		part := bar.Part()
+		jobs[i] = func(ctx context.Context) error {
+			return downloadAsset(ctx, client, asset, digests[asset.GetID()], path, part)
 		}
 	}
-	return nil
-}
 
-func getAssetsToDownload(ctx context.Context, client *github.Client) ([]*github.ReleaseAsset, error) {
-	latestAssets, err := getLatestReleaseAssets(ctx, client)
+	err = download.Parallel(ctx, viper.GetInt(config.DownloadConcurrency_key), jobs)
 	if err != nil {
-		return nil, fmt.Errorf("could not get the latest release's assets: %v", err)
+		bar.Fail()
+		return err
 	}
+	bar.Done()
 
-	assetsToDownload, err := filterAssets(latestAssets)
+	err = writeCacheInfo(path, release, assetsToDownload)
 	if err != nil {
-		return nil, fmt.Errorf("could not filter the assets to download: %v", err)
+		return errors.Wrap(err, "could not record the downloaded release")
 	}
-	return assetsToDownload, nil
+	return nil
 }
 
-func getLatestReleaseAssets(ctx context.Context, client *github.Client) ([]*github.ReleaseAsset, error) {
-	release, _, err := client.Repositories.GetLatestRelease(ctx, orgName, repoName)
-	if err != nil {
-		return nil, fmt.Errorf("could not get the latest release: %v", err)
+// planUnknownRelease records the download of a release that could not be looked up without logging in to GitHub
+func planUnknownRelease(path string, p *plan.Plan) {
+	tag := ReleaseTag()
+	if tag == "" {
+		tag = "latest"
 	}
+	p.Note("The GitHub login would be asked for to download the Unreal Engine installer")
+	p.Directory(path)
+	p.Download(fmt.Sprintf("Unreal Engine installer, %v release", tag), fmt.Sprintf("https://github.com/%v/%v/releases", orgName, repoName), -1, path)
+}
 
-	assets, _, err := client.Repositories.ListReleaseAssets(ctx, orgName, repoName, release.GetID(), nil)
+func getAssetsToDownload(ctx context.Context, client *github.Client, release *github.RepositoryRelease) ([]*github.ReleaseAsset, error) {
+	assets, _, err := client.Repositories.ListReleaseAssets(ctx, orgName, repoName, release.GetID(), &github.ListOptions{PerPage: 100})
 	if err != nil {
-		return nil, fmt.Errorf("could not list the release's assets")
+		return nil, fmt.Errorf("could not list the assets of release '%v': %v", release.GetTagName(), err)
 	}
 
-	return assets, nil
+	assetsToDownload, err := filterAssets(assets)
+	if err != nil {
+		return nil, fmt.Errorf("could not filter the assets to download: %v", err)
+	}
+	return assetsToDownload, nil
 }
 
-func ensureGithubAccess(ctx context.Context, client *github.Client) error {
+// EnsureGithubAccess checks that client can see the Unreal Engine fork, which requires being in the Epic Games organization
+func EnsureGithubAccess(ctx context.Context, client *github.Client) error {
 	_, _, err := client.Repositories.Get(ctx, orgName, repoName)
 	if err != nil {
-		return fmt.Errorf("could not get the repo: %v\nNOTE: This is most likely because you haven't joined the Epic Games organization. Please refer to the docs:\nhttps://docs.ficsit.app/satisfactory-modding/latest/Development/BeginnersGuide/dependencies.html#_unreal_engine_4_custom_engine", err)
+		return report.WithHint(fmt.Errorf("could not get the repo: %v", err),
+			"This is most likely because you haven't joined the Epic Games organization. Please refer to the docs:\nhttps://docs.ficsit.app/satisfactory-modding/latest/Development/BeginnersGuide/dependencies.html#_unreal_engine_4_custom_engine")
 	}
 
 	return nil
 }
 
-func downloadAsset(ctx context.Context, client *github.Client, asset *github.ReleaseAsset, dir string) error {
-	assetName := asset.GetName()
-
-	cfmt.Sequence.Printf("Downloading asset %v\n", assetName)
-	data, err := getAssetData(ctx, client, asset)
-	if err != nil {
-		return fmt.Errorf("could not get data for asset '%v': %v", assetName, err)
-	}
-
-	cfmt.Sequence.Printf("Writing asset '%v' to disk\n", assetName)
-	err = writeAssetFile(dir, assetName, data)
+// downloadAsset downloads asset to dir, checking it against digest if it is not empty
+func downloadAsset(ctx context.Context, client *github.Client, asset *github.ReleaseAsset, digest string, dir string, onProgress func(done int64)) error {
+	err := download.Download(ctx, download.Request{
+		Resolve: func(ctx context.Context) (string, error) {
+			return getAssetURL(ctx, client, asset)
+		},
+		Path:     filepath.Join(dir, asset.GetName()),
+		Size:     int64(asset.GetSize()),
+		Digest:   digest,
+		Progress: onProgress,
+	})
 	if err != nil {
-		return fmt.Errorf("could not write asset '%v' to disk: %v", assetName, err)
+		return fmt.Errorf("could not download asset '%v': %v", asset.GetName(), err)
 	}
 	return nil
 }
 
-func getAssetData(ctx context.Context, client *github.Client, asset *github.ReleaseAsset) ([]byte, error) {
-	content, _, err := client.Repositories.DownloadReleaseAsset(ctx, orgName, repoName, asset.GetID(), http.DefaultClient)
+// getAssetURL gets the signed URL assets of private repos redirect to. It expires after a few minutes
+func getAssetURL(ctx context.Context, client *github.Client, asset *github.ReleaseAsset) (string, error) {
+	content, redirectURL, err := client.Repositories.DownloadReleaseAsset(ctx, orgName, repoName, asset.GetID(), nil)
 	if err != nil {
-		return nil, fmt.Errorf("could not start downloading asset '%v': %v", asset.GetName(), err)
+		return "", fmt.Errorf("could not start downloading asset '%v': %v", asset.GetName(), err)
 	}
-	defer content.Close()
-
-	all, err := ioutil.ReadAll(content)
-	if err != nil {
-		return nil, fmt.Errorf("could not read the content of asset '%v': %v", asset.GetName(), err)
+	if content != nil {
+		content.Close()
+		return "", fmt.Errorf("asset '%v' was served directly instead of through a redirect", asset.GetName())
 	}
-
-	return all, nil
-}
-
-func writeAssetFile(targetDir, assetName string, data []byte) error {
-	filename := filepath.Join(targetDir, assetName)
-	return os.WriteFile(filename, data, 0666)
+	return redirectURL, nil
 }
 
-func runInstallerIfRequired(installerDir, installDir string, avoidUeReinstall bool) error {
+func runInstallerIfRequired(installerDir, installDir string, avoidUeReinstall bool, p *plan.Plan) error {
 	reinstall := false
 	other, err := hasOtherInstall()
 	if err != nil {
@@ -191,30 +329,86 @@ func runInstallerIfRequired(installerDir, installDir string, avoidUeReinstall bo
 
 	if reinstall && avoidUeReinstall {
 		cfmt.Sequence.Println("Skipping installing Unreal Engine again due to user-selected config option")
+		p.Note("Unreal Engine is already installed in '%s' and %v is set, it will not be reinstalled", installDir, config.UESkipReinstall_key)
 		return nil
 	}
 
-	if other && !reinstall {
+	replaced := false
+	if other && !reinstall && p.DryRun() {
+		current, err := getUninstallString()
+		if err != nil {
+			return errors.Wrap(err, "could not get the current uninstall string")
+		}
+		p.Note("The current uninstall entry will be saved in '%v' and restored if the install fails", state.Path())
+		p.RegistryChange(infoPath, "UninstallString", current, "")
+	} else if other && !reinstall {
+		err = saveReplacedUninstaller()
+		if err != nil {
+			return errors.Wrap(err, "could not save the current uninstall entry")
+		}
 		err = disableUninstaller()
 		if err != nil {
 			return errors.Wrap(err, "could not disable the uninstaller")
 		}
+		replaced = true
 	}
 
-	return runInstaller(installDir, installerDir)
+	err = runInstaller(installDir, installerDir, p)
+	if err != nil && replaced {
+		cfmt.Sequence.Println("Restoring the uninstaller of the existing install")
+		restoreErr := RepairUninstaller()
+		if restoreErr != nil {
+			cfmt.Warning.Printf("Could not restore the uninstaller of the existing install: %v. Run 'smei ue repair-uninstaller' to try again\n", restoreErr)
+		}
+	}
+	if err != nil || p.DryRun() {
+		return err
+	}
+	return recordInstall(installDir, reinstall)
 }
 
-func runInstaller(installDir, installerDir string) error {
-	cfmt.Sequence.Println("Running the UE installer")
-	filename := filepath.Join(installerDir, installerName)
+// recordInstall remembers that SMEI installed the engine in installDir, so that 'smei uninstall' may remove it.
+// Reinstalling over an install SMEI did not create does not make it SMEI's
+func recordInstall(installDir string, reinstall bool) error {
+	tag, err := CachedReleaseTag()
+	if err != nil {
+		return errors.Wrap(err, "could not get the installed release")
+	}
+	installDir, err = filepath.Abs(installDir)
+	if err != nil {
+		return errors.Wrap(err, "could not make the install path absolute")
+	}
+
+	return state.Update(func(s *state.State) {
+		if reinstall && (s.UE == nil || !state.SamePath(s.UE.Location, installDir)) {
+			return
+		}
+		s.UE = &state.UEInstall{
+			Install: state.Install{Location: installDir, Installed: time.Now()},
+			Tag:     tag,
+		}
+	})
+}
 
-	cmd := exec.Command(filename,
+func runInstaller(installDir, installerDir string, p *plan.Plan) error {
+	filename := filepath.Join(installerDir, installerName)
+	args := []string{
 		"/SILENT",
 		"/NORESTART",
 		fmt.Sprintf(`/DIR=%v`, installDir),
-	)
+	}
+
+	if p.DryRun() {
+		p.Directory(installDir)
+		p.Command(filename, args...)
+		return nil
+	}
 
-	return cmd.Run()
+	cfmt.Sequence.Println("Running the UE installer")
+	return progress.Run("UE installer", func(w io.Writer) error {
+		_, err := command.Run(context.Background(), command.Command{Name: filename, Args: args, Stdout: w, Stderr: w})
+		return err
+	})
 }
 
 func filterAssets(assets []*github.ReleaseAsset) ([]*github.ReleaseAsset, error) {
@@ -0,0 +1,117 @@
+package ue
+
+import (
+	"github.com/satisfactorymodding/SMEI/config"
+	"github.com/satisfactorymodding/SMEI/lib/command"
+	"github.com/satisfactorymodding/SMEI/lib/registry"
+	"github.com/satisfactorymodding/SMEI/lib/state"
+	"path/filepath"
+	"testing"
+)
+
+// useFakes points the config and cache at a temporary directory and replaces the registry and the command runner
+func useFakes(t *testing.T) (*registry.Fake, *command.Fake) {
+	configDir, cacheDir := config.ConfigDir, config.CacheDir
+	dir := t.TempDir()
+	config.ConfigDir = filepath.Join(dir, "config")
+	config.CacheDir = filepath.Join(dir, "cache")
+
+	reg := registry.NewFake()
+	previousRegistry := Registry
+	Registry = reg
+
+	runner := command.NewFake()
+	previousRunner := command.Use(runner)
+
+	t.Cleanup(func() {
+		config.ConfigDir, config.CacheDir = configDir, cacheDir
+		Registry = previousRegistry
+		command.Use(previousRunner)
+	})
+	return reg, runner
+}
+
+func TestRunInstallerIfRequired(t *testing.T) {
+	const otherUninstaller = `"C:\Other\unins000.exe"`
+	tests := []struct {
+		name string
+		// root is where an existing entry is registered, none if empty
+		root registry.Root
+		// sameLocation registers the existing entry in the install directory
+		sameLocation   bool
+		avoidReinstall bool
+		installerFails bool
+
+		installed       bool
+		uninstallString string
+		replaced        bool
+		recorded        bool
+	}{
+		{name: "no install", installed: true, recorded: true},
+		{name: "same path", root: registry.CurrentUser, sameLocation: true, installed: true, uninstallString: otherUninstaller},
+		{name: "same path, skip reinstall", root: registry.CurrentUser, sameLocation: true, avoidReinstall: true, uninstallString: otherUninstaller},
+		{name: "different path", root: registry.CurrentUser, installed: true, uninstallString: "", replaced: true, recorded: true},
+		{name: "different path, all users", root: registry.LocalMachine, installed: true, uninstallString: "", replaced: true, recorded: true},
+		{name: "different path, installer fails", root: registry.LocalMachine, installerFails: true, installed: true, uninstallString: otherUninstaller},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reg, runner := useFakes(t)
+			installDir := filepath.Join(t.TempDir(), "UE")
+			installerDir := t.TempDir()
+			if tt.installerFails {
+				runner.Script(installerName, command.Reply{ExitCode: 2})
+			}
+
+			otherLocation := `C:\Other`
+			if tt.sameLocation {
+				otherLocation = installDir
+			}
+			if tt.root != "" {
+				reg.Set(tt.root, infoPath, "DisplayName", "Unreal Engine")
+				reg.Set(tt.root, infoPath, "InstallLocation", otherLocation)
+				reg.Set(tt.root, infoPath, "UninstallString", otherUninstaller)
+			}
+
+			err := runInstallerIfRequired(installerDir, installDir, tt.avoidReinstall, nil)
+			if tt.installerFails != (err != nil) {
+				t.Fatalf("runInstallerIfRequired() error = %v", err)
+			}
+
+			calls := runner.CallsTo(installerName)
+			if installed := len(calls) == 1; installed != tt.installed {
+				t.Fatalf("installer run %v times", len(calls))
+			}
+			if tt.installed && calls[0].Args[2] != "/DIR="+installDir {
+				t.Errorf("installer args = %v", calls[0].Args)
+			}
+
+			if tt.root != "" {
+				uninstallString, _ := reg.Value(tt.root, infoPath, "UninstallString")
+				if uninstallString != tt.uninstallString {
+					t.Errorf("UninstallString = %q, want %q", uninstallString, tt.uninstallString)
+				}
+			}
+
+			s, err := state.Load()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if replaced := s.ReplacedUninstaller != nil; replaced != tt.replaced {
+				t.Fatalf("replaced uninstaller saved = %v, want %v", replaced, tt.replaced)
+			}
+			if tt.replaced {
+				want := state.UninstallEntry{Root: string(tt.root), DisplayName: "Unreal Engine", InstallLocation: otherLocation, UninstallString: otherUninstaller}
+				if *s.ReplacedUninstaller != want {
+					t.Errorf("replaced uninstaller = %+v, want %+v", *s.ReplacedUninstaller, want)
+				}
+			}
+			if recorded := s.UE != nil; recorded != tt.recorded {
+				t.Fatalf("install recorded = %v, want %v", recorded, tt.recorded)
+			}
+			if tt.recorded && !state.SamePath(s.UE.Location, installDir) {
+				t.Errorf("recorded install in '%v', want '%v'", s.UE.Location, installDir)
+			}
+		})
+	}
+}
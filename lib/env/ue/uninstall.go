@@ -0,0 +1,112 @@
+package ue
+
+import (
+	"context"
+	"github.com/satisfactorymodding/SMEI/lib/cfmt"
+	"github.com/satisfactorymodding/SMEI/lib/command"
+	"github.com/satisfactorymodding/SMEI/lib/progress"
+	"github.com/satisfactorymodding/SMEI/lib/report"
+	"github.com/satisfactorymodding/SMEI/lib/state"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// The uninstaller Inno Setup writes in the install directory
+const uninstallerName = "unins000.exe"
+
+// The uninstaller copies itself to a temporary directory and may return before the files are gone
+const uninstallTimeout = 30 * time.Minute
+
+// ErrNotInstalledBySMEI is returned when removing an install SMEI did not create
+var ErrNotInstalledBySMEI = errors.New("the Unreal Engine was not installed by SMEI")
+
+// Uninstall runs the uninstaller of the engine SMEI installed, then restores the uninstall entry of the install it replaced
+func Uninstall() error {
+	s, err := state.Load()
+	if err != nil {
+		return err
+	}
+	if s.UE == nil {
+		return report.WithHint(ErrNotInstalledBySMEI, "Only installs made by 'smei install' can be removed. Use 'Add or remove programs' for the others")
+	}
+	location := s.UE.Location
+
+	uninstaller, err := findUninstaller(location)
+	if err != nil {
+		return err
+	}
+	if uninstaller == "" {
+		cfmt.Warning.Printf("No Unreal Engine uninstaller found in '%v', it was already removed\n", location)
+	} else {
+		cfmt.Sequence.Printf("Uninstalling the Unreal Engine from '%v'\n", location)
+		err = progress.Run("UE uninstaller", func(w io.Writer) error {
+			_, err := command.Run(context.Background(), command.Command{
+				Name:   uninstaller,
+				Args:   []string{"/VERYSILENT", "/SUPPRESSMSGBOXES", "/NORESTART"},
+				Stdout: w,
+				Stderr: w,
+			})
+			if err != nil {
+				return err
+			}
+			return waitForRemoval(uninstaller, uninstallTimeout)
+		})
+		if err != nil {
+			return errors.Wrap(err, "could not run the Unreal Engine uninstaller")
+		}
+	}
+
+	err = state.Update(func(s *state.State) {
+		s.UE = nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if s.ReplacedUninstaller != nil {
+		cfmt.Sequence.Printf("Restoring the uninstaller of the install in '%v'\n", s.ReplacedUninstaller.InstallLocation)
+		err = RepairUninstaller()
+		if err != nil {
+			return errors.Wrap(err, "could not restore the previous uninstall entry")
+		}
+	}
+	return nil
+}
+
+// findUninstaller returns the uninstaller of the install in location, preferring the registered one. Empty if there is none
+func findUninstaller(location string) (string, error) {
+	entry, err := readUninstallEntry()
+	if err != nil {
+		return "", err
+	}
+	if entry != nil && entry.UninstallString != "" && state.SamePath(entry.InstallLocation, location) {
+		return strings.Trim(entry.UninstallString, `"`), nil
+	}
+
+	uninstaller := filepath.Join(location, uninstallerName)
+	_, err = os.Stat(uninstaller)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "could not check for the uninstaller")
+	}
+	return uninstaller, nil
+}
+
+func waitForRemoval(path string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		_, err := os.Stat(path)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		time.Sleep(2 * time.Second)
+	}
+	return errors.Errorf("'%v' was not removed after %v", path, timeout)
+}
@@ -0,0 +1,30 @@
+package vs
+
+import (
+	"github.com/satisfactorymodding/SMEI/config"
+	"github.com/satisfactorymodding/SMEI/lib/cfmt"
+	"github.com/satisfactorymodding/SMEI/lib/pipeline"
+
+	"github.com/spf13/viper"
+)
+
+// Step installs Visual Studio
+type Step struct{}
+
+func (Step) Name() string {
+	return "vs"
+}
+
+func (Step) Dependencies() []string {
+	return nil
+}
+
+func (Step) Check(ctx *pipeline.Context) (bool, error) {
+	info, err := Detect()
+	return info != nil && len(info.MissingComponents()) == 0, err
+}
+
+func (Step) Run(ctx *pipeline.Context) error {
+	cfmt.Sequence.Println("Installing Visual Studio...")
+	return Install(ctx.VSInstallPath, viper.GetBool(config.VSSkipReinstall_key), ctx.Plan)
+}
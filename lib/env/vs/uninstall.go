@@ -0,0 +1,56 @@
+package vs
+
+import (
+	"context"
+	"fmt"
+	"github.com/satisfactorymodding/SMEI/lib/cfmt"
+	"github.com/satisfactorymodding/SMEI/lib/command"
+	"github.com/satisfactorymodding/SMEI/lib/progress"
+	"github.com/satisfactorymodding/SMEI/lib/report"
+	"github.com/satisfactorymodding/SMEI/lib/state"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// ErrNotInstalledBySMEI is returned when removing an install SMEI did not create
+var ErrNotInstalledBySMEI = errors.New("Visual Studio was not installed by SMEI")
+
+// Uninstall removes the Visual Studio instance SMEI installed with the Visual Studio Installer
+func Uninstall() error {
+	s, err := state.Load()
+	if err != nil {
+		return err
+	}
+	if s.VS == nil {
+		return report.WithHint(ErrNotInstalledBySMEI, "Only installs made by 'smei install' can be removed. Use the Visual Studio Installer for the others")
+	}
+	location := s.VS.Location
+
+	_, err = os.Stat(location)
+	if os.IsNotExist(err) {
+		cfmt.Warning.Printf("Visual Studio is not in '%v' anymore, it was already removed\n", location)
+	} else {
+		setup := filepath.Join(os.ExpandEnv("${ProgramFiles(x86)}"), "Microsoft Visual Studio", "Installer", "setup.exe")
+		cfmt.Sequence.Printf("Uninstalling Visual Studio from '%v'\n", location)
+		err = progress.Run("VS uninstaller", func(w io.Writer) error {
+			_, err := command.Run(context.Background(), command.Command{
+				Name:         setup,
+				Args:         []string{"uninstall", "--installPath", location, "--passive", "--norestart"},
+				Stdout:       w,
+				Stderr:       w,
+				SuccessCodes: []int{rebootRequiredExitCode},
+			})
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("error while running the VS uninstaller: %v", err)
+		}
+	}
+
+	return state.Update(func(s *state.State) {
+		s.VS = nil
+	})
+}
@@ -0,0 +1,65 @@
+package vs
+
+import (
+	"github.com/satisfactorymodding/SMEI/config"
+	"github.com/satisfactorymodding/SMEI/lib/command"
+	"github.com/satisfactorymodding/SMEI/lib/state"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestUninstall(t *testing.T) {
+	tests := []struct {
+		name     string
+		exitCode int
+		failed   bool
+	}{
+		{name: "success", exitCode: 0},
+		{name: "reboot required", exitCode: rebootRequiredExitCode},
+		{name: "failure", exitCode: 1, failed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configDir := config.ConfigDir
+			config.ConfigDir = t.TempDir()
+			runner := command.NewFake()
+			previousRunner := command.Use(runner)
+			t.Cleanup(func() {
+				config.ConfigDir = configDir
+				command.Use(previousRunner)
+			})
+
+			location := t.TempDir()
+			err := state.Update(func(s *state.State) {
+				s.VS = &state.Install{Location: location, Installed: time.Now()}
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			runner.Script("setup", command.Reply{ExitCode: tt.exitCode})
+
+			err = Uninstall()
+			if tt.failed != (err != nil) {
+				t.Fatalf("Uninstall() error = %v", err)
+			}
+
+			calls := runner.Calls()
+			if len(calls) != 1 {
+				t.Fatalf("ran %v commands, want 1", len(calls))
+			}
+			want := []string{"uninstall", "--installPath", location, "--passive", "--norestart"}
+			if !reflect.DeepEqual(calls[0].Args, want) {
+				t.Errorf("setup args = %v, want %v", calls[0].Args, want)
+			}
+
+			s, err := state.Load()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if recorded := s.VS != nil; recorded != tt.failed {
+				t.Errorf("install still recorded = %v, want %v", recorded, tt.failed)
+			}
+		})
+	}
+}
@@ -1,24 +1,107 @@
 package vs
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"github.com/satisfactorymodding/SMEI/config"
+	"github.com/satisfactorymodding/SMEI/lib/cache"
 	"github.com/satisfactorymodding/SMEI/lib/cfmt"
+	"github.com/satisfactorymodding/SMEI/lib/command"
+	"github.com/satisfactorymodding/SMEI/lib/download"
+	"github.com/satisfactorymodding/SMEI/lib/plan"
+	"github.com/satisfactorymodding/SMEI/lib/progress"
+	"github.com/satisfactorymodding/SMEI/lib/state"
 	"io"
 	"net/http"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"time"
 )
 
 type Info struct {
-	Components []string
+	Location   string   `json:"location"`
+	Version    string   `json:"version"`
+	Components []string `json:"components"`
 }
 
-func Install(path string, avoidVsReinstall bool) error {
+type vswhereInstance struct {
+	InstallationPath    string `json:"installationPath"`
+	InstallationVersion string `json:"installationVersion"`
+	Packages            []struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+	} `json:"packages"`
+}
+
+// Detect finds the Visual Studio 2022 instance using vswhere. Returns nil if no instance could be found
+func Detect() (*Info, error) {
+	vswhere := filepath.Join(os.ExpandEnv("${ProgramFiles(x86)}"), "Microsoft Visual Studio", "Installer", "vswhere.exe")
+	_, err := os.Stat(vswhere)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not check for vswhere: %v", err)
+	}
+
+	var out bytes.Buffer
+	_, err = command.Run(context.Background(), command.Command{
+		Name:   vswhere,
+		Args:   []string{"-products", "*", "-version", "[17.0,18.0)", "-include", "packages", "-format", "json", "-utf8"},
+		Stdout: &out,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not run vswhere: %v", err)
+	}
+
+	var instances []vswhereInstance
+	err = json.Unmarshal(out.Bytes(), &instances)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse the vswhere output: %v", err)
+	}
+	if len(instances) == 0 {
+		return nil, nil
+	}
+
+	instance := instances[0]
+	info := &Info{
+		Location: instance.InstallationPath,
+		Version:  instance.InstallationVersion,
+	}
+	for _, pkg := range instance.Packages {
+		if pkg.Type == "Component" || pkg.Type == "Workload" {
+			info.Components = append(info.Components, pkg.ID)
+		}
+	}
+	return info, nil
+}
+
+// MissingComponents returns the components SMEI installs that this instance lacks
+func (i *Info) MissingComponents() []string {
+	var missing []string
+	for _, required := range RequiredComponents {
+		found := false
+		for _, component := range i.Components {
+			if component == required {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, required)
+		}
+	}
+	return missing
+}
+
+// Install downloads and runs the VS installer. Nothing is changed if p is not nil, it is filled instead
+func Install(path string, avoidVsReinstall bool, p *plan.Plan) error {
 	if avoidVsReinstall {
 		// TODO move this to a better part of the process
 		cfmt.Sequence.Println("Skipping installing Visual Stuido due to user-selected config option")
+		p.Note("%v is set, Visual Studio will not be installed", config.VSSkipReinstall_key)
 		return nil
 	}
 
@@ -26,7 +109,20 @@ func Install(path string, avoidVsReinstall bool) error {
 
 	targetPath, err := filepath.Abs(path)
 
-	filename, err := downloadInstaller()
+	filename := filepath.Join(cache.Dir(cache.VS), installerFilename)
+	args := []string{"--wait", "--in", filename + ".conf.json"}
+	if p.DryRun() {
+		size, err := getInstallerSize()
+		if err != nil {
+			return fmt.Errorf("could not get the size of the VS installer: %v", err)
+		}
+		p.Download(installerFilename, installerURL, size, filepath.Dir(filename))
+		p.Directory(makeConfig(targetPath)["installPath"].(string))
+		p.Command(filename, args...)
+		return nil
+	}
+
+	err = downloadInstaller(filename)
 	if err != nil {
 		return fmt.Errorf("could not download the VS installer: %v", err)
 	}
@@ -43,56 +139,94 @@ func Install(path string, avoidVsReinstall bool) error {
 		return fmt.Errorf("could not create the VS installer configuration file: %v", err)
 	}
 
-	cmd := exec.Command(filename, "--wait", "--in", configFilename)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	err = cmd.Run()
-	if err != nil && !isRebootExitCode(err) {
+	installPath := makeConfig(targetPath)["installPath"].(string)
+	existing, err := Detect()
+	if err != nil {
+		return fmt.Errorf("could not check for an existing Visual Studio install: %v", err)
+	}
+	created := existing == nil || !state.SamePath(existing.Location, installPath)
+
+	err = progress.Run("VS installer", func(w io.Writer) error {
+		_, err := command.Run(context.Background(), command.Command{
+			Name:         filename,
+			Args:         args,
+			Stdout:       w,
+			Stderr:       w,
+			SuccessCodes: []int{rebootRequiredExitCode},
+		})
+		return err
+	})
+	if err != nil {
 		return fmt.Errorf("error while running the VS installer: %v", err)
 	}
 
+	if !created {
+		return nil
+	}
+	err = state.Update(func(s *state.State) {
+		s.VS = &state.Install{Location: installPath, Installed: time.Now()}
+	})
+	if err != nil {
+		return fmt.Errorf("could not record the Visual Studio install: %v", err)
+	}
 	return nil
 }
 
 func makeConfigString(targetPath string) ([]byte, error) {
-	config, err := makeConfig(targetPath)
-	if err != nil {
-		return nil, fmt.Errorf("could not make the config: %v", err)
-	}
-
-	r, err := json.Marshal(config)
+	r, err := json.Marshal(makeConfig(targetPath))
 	if err != nil {
 		return nil, fmt.Errorf("could not marshal the json: %v", err)
 	}
 	return r, nil
 }
 
-func makeConfig(targetPath string) (map[string]interface{}, error) {
+func makeConfig(targetPath string) map[string]interface{} {
 	config := defaultConfigObject()
 	config["installPath"] = filepath.Join(targetPath, "VisualStudio")
-	return config, nil
+	return config
 }
 
-func downloadInstaller() (string, error) {
-	link := "https://aka.ms/vs/17/release/vs_community.exe"
-	filename := filepath.Join(os.TempDir(), "vs_Community.exe")
-	resp, err := http.Get(link)
+const installerURL = "https://aka.ms/vs/17/release/vs_community.exe"
+const installerFilename = "vs_Community.exe"
+
+func downloadInstaller(filename string) error {
+	size, err := getInstallerSize()
 	if err != nil {
-		return "", fmt.Errorf("could not get the installer file: %v", err)
+		return fmt.Errorf("could not get the size of the installer file: %v", err)
 	}
-	defer resp.Body.Close()
 
-	b, err := io.ReadAll(resp.Body)
+	bar := progress.NewBar(installerFilename, size)
+	// No digest: the bootstrapper behind installerURL changes with every Visual Studio update and Microsoft publishes no checksum for it
+	err = download.Download(context.Background(), download.Request{
+		URL:      installerURL,
+		Path:     filename,
+		Size:     size,
+		Progress: bar.Set,
+	})
 	if err != nil {
-		return "", fmt.Errorf("could not download the installer file: %v", err)
+		bar.Fail()
+		return fmt.Errorf("could not download the installer file: %v", err)
 	}
+	bar.Done()
+
+	return nil
+}
 
-	err = os.WriteFile(filename, b, 0644)
+func getInstallerSize() (int64, error) {
+	resp, err := http.Head(installerURL)
 	if err != nil {
-		return "", fmt.Errorf("could not save the installer file: %v", err)
+		return 0, fmt.Errorf("could not get the installer file: %v", err)
 	}
+	defer resp.Body.Close()
+	return resp.ContentLength, nil
+}
 
-	return filename, nil
+var RequiredComponents = []string{
+	"Microsoft.VisualStudio.Workload.NativeDesktop",
+	"Microsoft.VisualStudio.Workload.NativeGame",
+	"Microsoft.Net.Component.4.8.SDK",
+	"Microsoft.VisualStudio.Component.Windows10SDK.20348",
+	"Microsoft.VisualStudio.Component.VC.Tools.x86.x64",
 }
 
 func defaultConfigObject() map[string]interface{} {
@@ -100,20 +234,12 @@ func defaultConfigObject() map[string]interface{} {
 		"productId":      "Microsoft.VisualStudio.Product.Community",
 		"channelUri":     "https://aka.ms/vs/17/release/channel",
 		"addProductLang": []string{"en-US"},
-		"add": []string{
-			"Microsoft.VisualStudio.Workload.NativeDesktop",
-			"Microsoft.VisualStudio.Workload.NativeGame",
-			"Microsoft.Net.Component.4.8.SDK",
-			"Microsoft.VisualStudio.Component.Windows10SDK.20348",
-			"Microsoft.VisualStudio.Component.VC.Tools.x86.x64",
-		},
-		"passive":   true,
-		"force":     true,
-		"norestart": true,
+		"add":            RequiredComponents,
+		"passive":        true,
+		"force":          true,
+		"norestart":      true,
 	}
 }
 
-func isRebootExitCode(err error) bool {
-	code, ok := err.(*exec.ExitError)
-	return !ok || code.ExitCode() == 3010
-}
+// The VS installer succeeded but a reboot is required to complete the install
+const rebootRequiredExitCode = 3010
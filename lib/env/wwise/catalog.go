@@ -0,0 +1,210 @@
+package wwise
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/satisfactorymodding/SMEI/config"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mircearoata/wwise-cli/lib/wwise/client"
+	"github.com/mircearoata/wwise-cli/lib/wwise/product"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+const (
+	sdkProduct         = "wwise"
+	integrationProduct = "unrealintegration"
+	catalogFile        = "catalog.json"
+)
+
+type Version struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Stable bool   `json:"stable"`
+	// SdkVersion is the SDK version an integration version requires. Empty for SDK versions
+	SdkVersion string `json:"sdkVersion,omitempty"`
+	// UnrealVersions are the Unreal Engine versions an integration version supports, such as 5.1
+	UnrealVersions []string `json:"unrealVersions,omitempty"`
+}
+
+// Catalog lists the versions available from Audiokinetic, newest first
+type Catalog struct {
+	Fetched     time.Time `json:"fetched"`
+	SDK         []Version `json:"sdk"`
+	Integration []Version `json:"integration"`
+}
+
+// FetchCatalog gets the catalog using an authenticated client
+func FetchCatalog(wwiseClient *client.WwiseClient) (*Catalog, error) {
+	sdk, err := product.NewWwiseProduct(wwiseClient, sdkProduct).GetInfo()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get the SDK versions")
+	}
+	integration, err := product.NewWwiseProduct(wwiseClient, integrationProduct).GetInfo()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get the Unreal integration versions")
+	}
+
+	return &Catalog{
+		Fetched:     time.Now(),
+		SDK:         versions(sdk),
+		Integration: versions(integration),
+	}, nil
+}
+
+// LoadCatalog reads the cached catalog. Returns nil if it was never fetched
+func LoadCatalog() (*Catalog, error) {
+	data, err := os.ReadFile(catalogPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read the cached catalog")
+	}
+
+	var catalog Catalog
+	err = json.Unmarshal(data, &catalog)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse the cached catalog")
+	}
+	return &catalog, nil
+}
+
+func catalogPath() string {
+	return filepath.Join(viper.GetString(config.WwiseCacheDir_key), catalogFile)
+}
+
+// Save caches the catalog for offline use
+func (c *Catalog) Save() error {
+	err := os.MkdirAll(filepath.Dir(catalogPath()), 0755)
+	if err != nil {
+		return errors.Wrap(err, "could not create the Wwise cache directory")
+	}
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "could not marshal the catalog")
+	}
+
+	err = os.WriteFile(catalogPath(), data, 0644)
+	if err != nil {
+		return errors.Wrap(err, "could not cache the catalog")
+	}
+	return nil
+}
+
+func versions(info product.ProductInfo) []Version {
+	bundles := append([]product.Bundle{}, info.Bundles...)
+	sort.SliceStable(bundles, func(i, j int) bool {
+		return newer(bundles[i].Version, bundles[j].Version)
+	})
+
+	var r []Version
+	for _, bundle := range bundles {
+		v := Version{
+			ID:     versionID(bundle.Version, bundle.Version.Build),
+			Name:   bundle.VersionName,
+			Stable: bundle.Stable != 0,
+		}
+		// Integrations are built against an SDK build of the same year.major.minor
+		if bundle.ProductDependentData.WwiseSdkBuild != 0 {
+			v.SdkVersion = versionID(bundle.Version, bundle.ProductDependentData.WwiseSdkBuild)
+		}
+		for _, unreal := range bundle.ProductDependentData.SupportedUnrealVersions {
+			v.UnrealVersions = append(v.UnrealVersions, fmt.Sprintf("%d.%d", unreal.Major, unreal.Minor))
+		}
+		r = append(r, v)
+	}
+	return r
+}
+
+func newer(a, b product.Version) bool {
+	if a.Year != b.Year {
+		return a.Year > b.Year
+	}
+	if a.Major != b.Major {
+		return a.Major > b.Major
+	}
+	if a.Minor != b.Minor {
+		return a.Minor > b.Minor
+	}
+	return a.Build > b.Build
+}
+
+// versionID formats versions the way the config keys and the Wwise API expect them
+func versionID(v product.Version, build int) string {
+	return fmt.Sprintf("%d.%d.%d.%d", v.Year, v.Major, v.Minor, build)
+}
+
+func find(versions []Version, id string) *Version {
+	for i := range versions {
+		if versions[i].ID == id {
+			return &versions[i]
+		}
+	}
+	return nil
+}
+
+// trimProduct removes the product prefix versions can be written with, such as wwise.2021.1.8.7831
+func trimProduct(product, id string) string {
+	return strings.TrimPrefix(id, product+".")
+}
+
+func (c *Catalog) FindSDK(id string) *Version {
+	return find(c.SDK, trimProduct(sdkProduct, id))
+}
+
+func (c *Catalog) FindIntegration(id string) *Version {
+	return find(c.Integration, trimProduct(integrationProduct, id))
+}
+
+// IntegrationsFor returns the integration versions requiring the given SDK version, with or without its product prefix, newest first
+func (c *Catalog) IntegrationsFor(sdkVersion string) []Version {
+	sdkVersion = trimProduct(sdkProduct, sdkVersion)
+	var r []Version
+	for _, v := range c.Integration {
+		if v.SdkVersion == sdkVersion {
+			r = append(r, v)
+		}
+	}
+	return r
+}
+
+// Validate checks that both versions exist, with or without their product prefix. The returned warnings describe problems that do not prevent the versions from being used.
+// unrealVersion is the major.minor version of the installed engine, the check is skipped if empty
+func (c *Catalog) Validate(sdkVersion, integrationVersion, unrealVersion string) ([]string, error) {
+	if c.FindSDK(sdkVersion) == nil {
+		return nil, errors.Errorf("unknown Wwise SDK version '%v'. Run 'smei config wwise versions' to list them", sdkVersion)
+	}
+	integration := c.FindIntegration(integrationVersion)
+	if integration == nil {
+		return nil, errors.Errorf("unknown Wwise Unreal integration version '%v'. Run 'smei config wwise versions' to list them", integrationVersion)
+	}
+
+	var warnings []string
+	if integration.SdkVersion != "" && integration.SdkVersion != trimProduct(sdkProduct, sdkVersion) {
+		warnings = append(warnings, fmt.Sprintf("Unreal integration %v is built for SDK %v, not %v. The integration will use SDK %v",
+			integrationVersion, integration.SdkVersion, sdkVersion, integration.SdkVersion))
+	}
+	if unrealVersion != "" && len(integration.UnrealVersions) > 0 && !contains(integration.UnrealVersions, unrealVersion) {
+		warnings = append(warnings, fmt.Sprintf("Unreal integration %v does not support Unreal Engine %v", integrationVersion, unrealVersion))
+	}
+	if !integration.Stable {
+		warnings = append(warnings, fmt.Sprintf("Unreal integration %v is not a stable release", integrationVersion))
+	}
+	return warnings, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
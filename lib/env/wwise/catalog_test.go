@@ -0,0 +1,59 @@
+package wwise
+
+import (
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	catalog := &Catalog{
+		SDK: []Version{{ID: "2021.1.8.7831", Stable: true}, {ID: "2022.1.0.8070", Stable: true}},
+		Integration: []Version{
+			{ID: "2021.1.8.2285", Stable: true, SdkVersion: "2021.1.8.7831", UnrealVersions: []string{"4.26"}},
+		},
+	}
+	tests := []struct {
+		name        string
+		sdk         string
+		integration string
+		unreal      string
+		warnings    int
+		failed      bool
+	}{
+		{name: "matching versions", sdk: "2021.1.8.7831", integration: "2021.1.8.2285"},
+		{name: "prefixed versions", sdk: "wwise.2021.1.8.7831", integration: "unrealintegration.2021.1.8.2285"},
+		{name: "other SDK", sdk: "wwise.2022.1.0.8070", integration: "2021.1.8.2285", warnings: 1},
+		{name: "unsupported engine", sdk: "2021.1.8.7831", integration: "2021.1.8.2285", unreal: "5.1", warnings: 1},
+		{name: "unknown SDK", sdk: "2019.2.0.1", integration: "2021.1.8.2285", failed: true},
+		{name: "unknown integration", sdk: "2021.1.8.7831", integration: "wwise.2021.1.8.2285", failed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings, err := catalog.Validate(tt.sdk, tt.integration, tt.unreal)
+			if tt.failed != (err != nil) {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			if len(warnings) != tt.warnings {
+				t.Errorf("Validate() warnings = %v, want %v of them", warnings, tt.warnings)
+			}
+		})
+	}
+}
+
+func TestIntegrationsFor(t *testing.T) {
+	catalog := &Catalog{
+		Integration: []Version{
+			{ID: "2021.1.8.2285", SdkVersion: "2021.1.8.7831"},
+			{ID: "2021.1.7.2100", SdkVersion: "2021.1.7.7796"},
+			{ID: "2021.1.8.2200", SdkVersion: "2021.1.8.7831"},
+		},
+	}
+	for _, sdk := range []string{"2021.1.8.7831", "wwise.2021.1.8.7831"} {
+		integrations := catalog.IntegrationsFor(sdk)
+		if len(integrations) != 2 || integrations[0].ID != "2021.1.8.2285" || integrations[1].ID != "2021.1.8.2200" {
+			t.Errorf("IntegrationsFor(%v) = %+v, want the two integrations of that SDK", sdk, integrations)
+		}
+	}
+	if integrations := catalog.IntegrationsFor("2019.2.0.1"); len(integrations) != 0 {
+		t.Errorf("IntegrationsFor() of an unknown SDK = %+v, want none", integrations)
+	}
+}
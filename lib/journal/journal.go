@@ -0,0 +1,141 @@
+package journal
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/satisfactorymodding/SMEI/config"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const folder = "journals"
+
+// Journal records which install steps completed for a given target
+type Journal struct {
+	Target    string               `json:"target"`
+	Completed map[string]time.Time `json:"completed"`
+	path      string
+	readOnly  bool
+}
+
+// Load reads the journal of target. A missing journal is not an error, an empty one is returned instead
+func Load(target string) (*Journal, error) {
+	target, err := filepath.Abs(target)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not make the target path absolute")
+	}
+
+	j := &Journal{
+		Target:    target,
+		Completed: map[string]time.Time{},
+		path:      pathFor(target),
+	}
+
+	data, err := os.ReadFile(j.path)
+	if os.IsNotExist(err) {
+		return j, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read the journal")
+	}
+
+	err = json.Unmarshal(data, j)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse the journal")
+	}
+	if j.Completed == nil {
+		j.Completed = map[string]time.Time{}
+	}
+
+	return j, nil
+}
+
+// ReadOnly makes further changes to the journal only apply in memory
+func (j *Journal) ReadOnly() {
+	j.readOnly = true
+}
+
+func pathFor(target string) string {
+	hash := sha1.Sum([]byte(target))
+	return filepath.Join(config.ConfigDir, folder, hex.EncodeToString(hash[:8])+".json")
+}
+
+func (j *Journal) IsComplete(step string) bool {
+	_, ok := j.Completed[step]
+	return ok
+}
+
+// Complete records step as done and persists the journal
+func (j *Journal) Complete(step string) error {
+	j.Completed[step] = time.Now()
+	return j.save()
+}
+
+// Forget removes steps from the journal so they run again, and persists the journal
+func (j *Journal) Forget(steps ...string) error {
+	for _, step := range steps {
+		delete(j.Completed, step)
+	}
+	return j.save()
+}
+
+// RestartFrom forgets step and every step after it in ordered, so they run again, and persists the journal
+func (j *Journal) RestartFrom(ordered []string, step string) error {
+	for i, name := range ordered {
+		if name == step {
+			return j.Forget(ordered[i:]...)
+		}
+	}
+	return errors.Errorf("unknown step '%v', expected one of %v", step, ordered)
+}
+
+// Reset forgets every step
+func (j *Journal) Reset() error {
+	j.Completed = map[string]time.Time{}
+	return j.save()
+}
+
+// Delete removes the persisted journal of target, if any
+func Delete(target string) error {
+	target, err := filepath.Abs(target)
+	if err != nil {
+		return errors.Wrap(err, "could not make the target path absolute")
+	}
+	err = os.Remove(pathFor(target))
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "could not remove the journal")
+	}
+	return nil
+}
+
+func (j *Journal) save() error {
+	if j.readOnly {
+		return nil
+	}
+
+	err := os.MkdirAll(filepath.Dir(j.path), 0744)
+	if err != nil {
+		return errors.Wrap(err, "could not create the journal directory")
+	}
+
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "could not marshal the journal")
+	}
+
+	// Write then rename so a crash mid-write never leaves a corrupt journal
+	tmp := j.path + ".tmp"
+	err = os.WriteFile(tmp, data, 0644)
+	if err != nil {
+		return errors.Wrap(err, "could not write the journal")
+	}
+	err = os.Rename(tmp, j.path)
+	if err != nil {
+		return errors.Wrap(err, "could not replace the journal")
+	}
+	return nil
+}
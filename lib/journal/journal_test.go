@@ -0,0 +1,163 @@
+package journal
+
+import (
+	"github.com/satisfactorymodding/SMEI/config"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// useTempConfigDir points the config at a temporary directory and returns a target to journal
+func useTempConfigDir(t *testing.T) string {
+	configDir := config.ConfigDir
+	config.ConfigDir = t.TempDir()
+	t.Cleanup(func() {
+		config.ConfigDir = configDir
+	})
+	return filepath.Join(t.TempDir(), "SatisfactoryModLoader")
+}
+
+func completed(j *Journal) []string {
+	var r []string
+	for step := range j.Completed {
+		r = append(r, step)
+	}
+	sort.Strings(r)
+	return r
+}
+
+func TestJournal(t *testing.T) {
+	target := useTempConfigDir(t)
+
+	j, err := Load(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(j.Completed) != 0 {
+		t.Fatalf("new journal completed %v", completed(j))
+	}
+
+	for _, step := range []string{"vs", "ue", "wwise"} {
+		err = j.Complete(step)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := os.Stat(j.path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temporary file left behind after saving, stat error = %v", err)
+	}
+
+	j, err = Load(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"ue", "vs", "wwise"}; !reflect.DeepEqual(completed(j), want) {
+		t.Errorf("reloaded journal completed %v, want %v", completed(j), want)
+	}
+
+	err = j.RestartFrom([]string{"vs", "ue", "wwise"}, "ue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	j, err = Load(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"vs"}; !reflect.DeepEqual(completed(j), want) {
+		t.Errorf("journal completed %v after restarting from 'ue', want %v", completed(j), want)
+	}
+
+	err = Delete(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	j, err = Load(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(j.Completed) != 0 {
+		t.Errorf("journal completed %v after being deleted", completed(j))
+	}
+
+	err = Delete(target)
+	if err != nil {
+		t.Errorf("Delete() of a missing journal error = %v", err)
+	}
+}
+
+func TestRestartFromUnknownStep(t *testing.T) {
+	j, err := Load(useTempConfigDir(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = j.Complete("vs")
+
+	err = j.RestartFrom([]string{"vs", "ue"}, "sml")
+	if err == nil {
+		t.Fatal("RestartFrom() an unknown step did not fail")
+	}
+	if !j.IsComplete("vs") {
+		t.Errorf("RestartFrom() an unknown step forgot completed steps")
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	target := useTempConfigDir(t)
+	j, err := Load(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = j.Complete("vs")
+
+	j, err = Load(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	j.ReadOnly()
+	for _, change := range []func() error{
+		func() error { return j.Complete("ue") },
+		func() error { return j.RestartFrom([]string{"vs", "ue"}, "vs") },
+		j.Reset,
+	} {
+		err = change()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	j, err = Load(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"vs"}; !reflect.DeepEqual(completed(j), want) {
+		t.Errorf("journal completed %v after read-only changes, want %v", completed(j), want)
+	}
+}
+
+func TestLoadCorrupt(t *testing.T) {
+	target := useTempConfigDir(t)
+	path := pathFor(mustAbs(t, target))
+	err := os.MkdirAll(filepath.Dir(path), 0744)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(path, []byte("{"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = Load(target)
+	if err == nil {
+		t.Errorf("Load() of a corrupt journal did not fail")
+	}
+}
+
+func mustAbs(t *testing.T, path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return abs
+}
@@ -0,0 +1,28 @@
+// Package keyring stores secrets in the OS credential store:
+// Windows Credential Manager, macOS Keychain or the Secret Service on Linux
+package keyring
+
+import (
+	"github.com/pkg/errors"
+)
+
+var ErrNotFound = errors.New("secret not found in the keyring")
+
+var ErrUnsupported = errors.New("no keyring is available on this platform")
+
+func Get(service, key string) (string, error) {
+	return get(service, key)
+}
+
+func Set(service, key, value string) error {
+	return set(service, key, value)
+}
+
+// Delete removes a secret. Deleting a secret that does not exist is not an error
+func Delete(service, key string) error {
+	err := del(service, key)
+	if err == ErrNotFound {
+		return nil
+	}
+	return err
+}
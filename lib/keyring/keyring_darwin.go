@@ -0,0 +1,57 @@
+package keyring
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Exit code of the security tool when the item could not be found
+const errSecItemNotFound = 44
+
+func get(service, key string) (string, error) {
+	out, err := exec.Command("security", "find-generic-password", "-s", service, "-a", key, "-w").Output()
+	if isNotFound(err) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "could not read from the keychain")
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+// set sends the command to the interactive mode of the security tool through stdin, as any process can read the arguments of
+// another. The value is hex encoded so that it never needs quoting
+func set(service, key, value string) error {
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %v -a %v -X %v\n", quote(service), quote(key), hex.EncodeToString([]byte(value))))
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return errors.Wrapf(err, "could not write to the keychain: %v", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// quote makes s a single argument of the interactive mode of the security tool
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
+
+func del(service, key string) error {
+	err := exec.Command("security", "delete-generic-password", "-s", service, "-a", key).Run()
+	if isNotFound(err) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return errors.Wrap(err, "could not delete from the keychain")
+	}
+	return nil
+}
+
+func isNotFound(err error) bool {
+	exitErr, ok := err.(*exec.ExitError)
+	return ok && exitErr.ExitCode() == errSecItemNotFound
+}
@@ -0,0 +1,39 @@
+package keyring
+
+import (
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// The Secret Service is used through secret-tool, from libsecret
+
+func get(service, key string) (string, error) {
+	out, err := exec.Command("secret-tool", "lookup", "service", service, "key", key).Output()
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); ok && len(out) == 0 {
+			return "", ErrNotFound
+		}
+		return "", errors.Wrap(err, "could not read from the secret service")
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+func set(service, key, value string) error {
+	cmd := exec.Command("secret-tool", "store", "--label="+service+" "+key, "service", service, "key", key)
+	cmd.Stdin = strings.NewReader(value)
+	err := cmd.Run()
+	if err != nil {
+		return errors.Wrap(err, "could not write to the secret service")
+	}
+	return nil
+}
+
+func del(service, key string) error {
+	err := exec.Command("secret-tool", "clear", "service", service, "key", key).Run()
+	if err != nil {
+		return errors.Wrap(err, "could not delete from the secret service")
+	}
+	return nil
+}
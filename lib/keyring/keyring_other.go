@@ -0,0 +1,16 @@
+//go:build !windows && !darwin && !linux
+// +build !windows,!darwin,!linux
+
+package keyring
+
+func get(service, key string) (string, error) {
+	return "", ErrUnsupported
+}
+
+func set(service, key, value string) error {
+	return ErrUnsupported
+}
+
+func del(service, key string) error {
+	return ErrUnsupported
+}
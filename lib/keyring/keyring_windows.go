@@ -0,0 +1,104 @@
+package keyring
+
+import (
+	"unsafe"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/windows"
+)
+
+var (
+	advapi32       = windows.NewLazySystemDLL("advapi32.dll")
+	procCredRead   = advapi32.NewProc("CredReadW")
+	procCredWrite  = advapi32.NewProc("CredWriteW")
+	procCredDelete = advapi32.NewProc("CredDeleteW")
+	procCredFree   = advapi32.NewProc("CredFree")
+)
+
+const credTypeGeneric = 1
+const credPersistLocalMachine = 2
+
+// Mirrors CREDENTIALW
+type credential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        windows.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+func targetName(service, key string) (*uint16, error) {
+	return windows.UTF16PtrFromString(service + ":" + key)
+}
+
+func get(service, key string) (string, error) {
+	target, err := targetName(service, key)
+	if err != nil {
+		return "", errors.Wrap(err, "invalid target name")
+	}
+
+	var cred *credential
+	r, _, err := procCredRead.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0, uintptr(unsafe.Pointer(&cred)))
+	if r == 0 {
+		if err == windows.ERROR_NOT_FOUND {
+			return "", ErrNotFound
+		}
+		return "", errors.Wrap(err, "could not read the credential")
+	}
+	defer procCredFree.Call(uintptr(unsafe.Pointer(cred)))
+
+	blob := unsafe.Slice(cred.CredentialBlob, cred.CredentialBlobSize)
+	return string(blob), nil
+}
+
+func set(service, key, value string) error {
+	target, err := targetName(service, key)
+	if err != nil {
+		return errors.Wrap(err, "invalid target name")
+	}
+	user, err := windows.UTF16PtrFromString(key)
+	if err != nil {
+		return errors.Wrap(err, "invalid user name")
+	}
+
+	blob := []byte(value)
+	cred := credential{
+		Type:               credTypeGeneric,
+		TargetName:         target,
+		CredentialBlobSize: uint32(len(blob)),
+		Persist:            credPersistLocalMachine,
+		UserName:           user,
+	}
+	if len(blob) > 0 {
+		cred.CredentialBlob = &blob[0]
+	}
+
+	r, _, err := procCredWrite.Call(uintptr(unsafe.Pointer(&cred)), 0)
+	if r == 0 {
+		return errors.Wrap(err, "could not write the credential")
+	}
+	return nil
+}
+
+func del(service, key string) error {
+	target, err := targetName(service, key)
+	if err != nil {
+		return errors.Wrap(err, "invalid target name")
+	}
+
+	r, _, err := procCredDelete.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0)
+	if r == 0 {
+		if err == windows.ERROR_NOT_FOUND {
+			return ErrNotFound
+		}
+		return errors.Wrap(err, "could not delete the credential")
+	}
+	return nil
+}
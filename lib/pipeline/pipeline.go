@@ -0,0 +1,52 @@
+package pipeline
+
+import (
+	"github.com/satisfactorymodding/SMEI/lib/credentials"
+	"github.com/satisfactorymodding/SMEI/lib/plan"
+)
+
+// Step is a unit of work of the install pipeline
+type Step interface {
+	Name() string
+	// Dependencies are the names of the steps that must run before this one, if they are selected
+	Dependencies() []string
+	// Check reports whether the work of the step is already done. It is only called when resuming, to verify the steps
+	// the journal records as completed; steps that are not journaled always run
+	Check(ctx *Context) (bool, error)
+	Run(ctx *Context) error
+}
+
+// Preparer is implemented by steps that need something before any step runs, such as user input
+type Preparer interface {
+	Prepare(ctx *Context) error
+}
+
+// Context is the state shared by the steps of a pipeline run
+type Context struct {
+	Target        string
+	UEInstallDir  string
+	InstallerDir  string
+	VSInstallPath string
+	Wwise         *credentials.WwiseAuth
+	// Plan is filled instead of making changes if not nil
+	Plan *plan.Plan
+}
+
+var registered []Step
+
+// Register adds steps to the install pipeline. Registration order is used to order steps that do not depend on each other
+func Register(steps ...Step) {
+	registered = append(registered, steps...)
+}
+
+func Registered() []Step {
+	return registered
+}
+
+func Names(steps []Step) []string {
+	r := make([]string, len(steps))
+	for i, step := range steps {
+		r[i] = step.Name()
+	}
+	return r
+}
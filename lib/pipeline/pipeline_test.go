@@ -0,0 +1,183 @@
+package pipeline
+
+import (
+	"github.com/satisfactorymodding/SMEI/config"
+	"github.com/satisfactorymodding/SMEI/lib/journal"
+	"github.com/satisfactorymodding/SMEI/lib/report"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+// fakeStep records its runs in log. done is what Check reports
+type fakeStep struct {
+	name         string
+	dependencies []string
+	done         bool
+	checkErr     error
+	checked      bool
+	log          *[]string
+}
+
+func (s *fakeStep) Name() string {
+	return s.name
+}
+
+func (s *fakeStep) Dependencies() []string {
+	return s.dependencies
+}
+
+func (s *fakeStep) Check(ctx *Context) (bool, error) {
+	s.checked = true
+	return s.done, s.checkErr
+}
+
+func (s *fakeStep) Run(ctx *Context) error {
+	*s.log = append(*s.log, s.name)
+	return nil
+}
+
+func step(name string, dependencies ...string) *fakeStep {
+	return &fakeStep{name: name, dependencies: dependencies}
+}
+
+func TestOrder(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []Step
+		want  []string
+		err   string
+	}{
+		{
+			name:  "independent steps keep their order",
+			steps: []Step{step("vs"), step("ue"), step("wwise")},
+			want:  []string{"vs", "ue", "wwise"},
+		},
+		{
+			name:  "dependencies come first",
+			steps: []Step{step("wwise", "ue"), step("sml", "wwise", "vs"), step("ue"), step("vs")},
+			want:  []string{"ue", "wwise", "vs", "sml"},
+		},
+		{
+			name:  "dependency cycle",
+			steps: []Step{step("ue", "wwise"), step("wwise", "sml"), step("sml", "ue")},
+			err:   "dependency cycle",
+		},
+		{
+			name:  "unknown dependency",
+			steps: []Step{step("wwise", "unreal")},
+			err:   "depends on unknown step 'unreal'",
+		},
+		{
+			name:  "registered twice",
+			steps: []Step{step("ue"), step("ue")},
+			err:   "registered twice",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ordered, err := Order(tt.steps)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("Order() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := Names(ordered); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Order() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelected(t *testing.T) {
+	steps := []Step{step("vs"), step("ue"), step("wwise", "ue"), step("sml", "ue")}
+	tests := []struct {
+		name string
+		only []string
+		skip []string
+		want []string
+		err  bool
+	}{
+		{name: "everything", want: []string{"vs", "ue", "wwise", "sml"}},
+		{name: "only", only: []string{"sml", "vs"}, want: []string{"vs", "sml"}},
+		{name: "skip", skip: []string{"ue"}, want: []string{"vs", "wwise", "sml"}},
+		{name: "only and skip", only: []string{"ue", "wwise"}, skip: []string{"ue"}, want: []string{"wwise"}},
+		{name: "unknown only", only: []string{"unreal"}, err: true},
+		{name: "unknown skip", skip: []string{"unreal"}, err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Runner{Steps: steps, Only: tt.only, Skip: tt.skip}
+			selected, err := r.Selected()
+			if tt.err != (err != nil) {
+				t.Fatalf("Selected() error = %v", err)
+			}
+			if got := Names(selected); !tt.err && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Selected() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResume(t *testing.T) {
+	tests := []struct {
+		name   string
+		resume bool
+		// done and checkErr are what Check reports for the journaled step
+		done     bool
+		checkErr error
+		ran      []string
+		checked  bool
+	}{
+		{name: "verified step is skipped", resume: true, done: true, ran: []string{"ue"}, checked: true},
+		{name: "unverified step runs again", resume: true, ran: []string{"vs", "ue"}, checked: true},
+		{name: "failed check runs again", resume: true, done: true, checkErr: errors.New("no access"), ran: []string{"vs", "ue"}, checked: true},
+		{name: "not resuming runs everything", done: true, ran: []string{"vs", "ue"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configDir := config.ConfigDir
+			config.ConfigDir = t.TempDir()
+			t.Cleanup(func() {
+				config.ConfigDir = configDir
+			})
+
+			j, err := journal.Load(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = j.Complete("vs")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var ran []string
+			vs := &fakeStep{name: "vs", done: tt.done, checkErr: tt.checkErr, log: &ran}
+			ue := &fakeStep{name: "ue", dependencies: []string{"vs"}, done: true, log: &ran}
+			r := &Runner{Steps: []Step{vs, ue}, Journal: j, Resume: tt.resume, Report: report.Start()}
+
+			err = r.Run(&Context{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(ran, tt.ran) {
+				t.Errorf("ran %v, want %v", ran, tt.ran)
+			}
+			if vs.checked != tt.checked {
+				t.Errorf("journaled step checked = %v, want %v", vs.checked, tt.checked)
+			}
+			if ue.checked {
+				t.Errorf("step that was not journaled was checked")
+			}
+			if !j.IsComplete("ue") {
+				t.Errorf("step that ran was not journaled")
+			}
+		})
+	}
+}
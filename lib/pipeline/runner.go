@@ -0,0 +1,163 @@
+package pipeline
+
+import (
+	"github.com/satisfactorymodding/SMEI/lib/cfmt"
+	"github.com/satisfactorymodding/SMEI/lib/journal"
+	"github.com/satisfactorymodding/SMEI/lib/report"
+
+	"github.com/pkg/errors"
+)
+
+type Runner struct {
+	Steps []Step
+	// Only runs these steps if not empty
+	Only []string
+	Skip []string
+	// Journal records completed steps. Optional
+	Journal *journal.Journal
+	// Resume skips the journaled steps that Check confirms
+	Resume bool
+	Report *report.Run
+}
+
+// Order sorts steps so that every step comes after its dependencies, otherwise keeping the given order
+func Order(steps []Step) ([]Step, error) {
+	byName := map[string]Step{}
+	for _, step := range steps {
+		if _, ok := byName[step.Name()]; ok {
+			return nil, errors.Errorf("step '%v' is registered twice", step.Name())
+		}
+		byName[step.Name()] = step
+	}
+
+	var r []Step
+	done := map[string]bool{}
+	visiting := map[string]bool{}
+	var visit func(step Step) error
+	visit = func(step Step) error {
+		name := step.Name()
+		if done[name] {
+			return nil
+		}
+		if visiting[name] {
+			return errors.Errorf("dependency cycle through step '%v'", name)
+		}
+		visiting[name] = true
+		for _, dependency := range step.Dependencies() {
+			dependencyStep, ok := byName[dependency]
+			if !ok {
+				return errors.Errorf("step '%v' depends on unknown step '%v'", name, dependency)
+			}
+			err := visit(dependencyStep)
+			if err != nil {
+				return err
+			}
+		}
+		visiting[name] = false
+		done[name] = true
+		r = append(r, step)
+		return nil
+	}
+
+	for _, step := range steps {
+		err := visit(step)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Selected returns the ordered steps that Only and Skip let through
+func (r *Runner) Selected() ([]Step, error) {
+	ordered, err := Order(r.Steps)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not order the steps")
+	}
+
+	names := Names(ordered)
+	for _, name := range append(append([]string{}, r.Only...), r.Skip...) {
+		if !contains(names, name) {
+			return nil, errors.Errorf("unknown step '%v', expected one of %v", name, names)
+		}
+	}
+
+	var selected []Step
+	for _, step := range ordered {
+		if len(r.Only) > 0 && !contains(r.Only, step.Name()) {
+			continue
+		}
+		if contains(r.Skip, step.Name()) {
+			continue
+		}
+		selected = append(selected, step)
+	}
+	return selected, nil
+}
+
+// Run prepares then runs the selected steps in order, stopping at the first failure
+func (r *Runner) Run(ctx *Context) error {
+	selected, err := r.Selected()
+	if err != nil {
+		return err
+	}
+
+	for _, step := range selected {
+		preparer, ok := step.(Preparer)
+		if !ok {
+			continue
+		}
+		err = r.Report.Step(step.Name()+"-prepare", func() error {
+			return preparer.Prepare(ctx)
+		})
+		if err != nil {
+			return errors.Wrapf(err, "could not prepare step '%v'", step.Name())
+		}
+	}
+
+	for _, step := range selected {
+		err = r.runStep(ctx, step)
+		if err != nil {
+			return errors.Wrapf(err, "step '%v' failed", step.Name())
+		}
+	}
+	return nil
+}
+
+func (r *Runner) runStep(ctx *Context, step Step) error {
+	name := step.Name()
+	if r.Resume && r.Journal != nil && r.Journal.IsComplete(name) {
+		done, err := step.Check(ctx)
+		if err == nil && done {
+			cfmt.Sequence.Printf("Skipping step '%v', it was already completed\n", name)
+			ctx.Plan.Note("Step '%v' was already completed, it will be skipped", name)
+			r.Report.Skip(name)
+			return nil
+		}
+		cfmt.Warning.Printf("Step '%v' was completed but could not be verified, running it again\n", name)
+	}
+
+	err := r.Report.Step(name, func() error {
+		return step.Run(ctx)
+	})
+	if err != nil {
+		return err
+	}
+
+	if r.Journal != nil {
+		err = r.Journal.Complete(name)
+		if err != nil {
+			return errors.Wrap(err, "could not record the step in the journal")
+		}
+	}
+	return nil
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,159 @@
+package plan
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// Plan records what an install would do instead of doing it. A nil *Plan means changes are actually made,
+// so every method is safe to call on nil
+type Plan struct {
+	Downloads   []Download       `json:"downloads"`
+	Directories []string         `json:"directories"`
+	Registry    []RegistryChange `json:"registry"`
+	Commands    []Command        `json:"commands"`
+	Notes       []string         `json:"notes"`
+}
+
+type Download struct {
+	Name        string `json:"name"`
+	URL         string `json:"url"`
+	Size        int64  `json:"size"`
+	Destination string `json:"destination"`
+}
+
+type RegistryChange struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+type Command struct {
+	Path string   `json:"path"`
+	Args []string `json:"args"`
+}
+
+func New() *Plan {
+	return &Plan{
+		Downloads:   []Download{},
+		Directories: []string{},
+		Registry:    []RegistryChange{},
+		Commands:    []Command{},
+		Notes:       []string{},
+	}
+}
+
+func (p *Plan) DryRun() bool {
+	return p != nil
+}
+
+func (p *Plan) Download(name, url string, size int64, destination string) {
+	if p == nil {
+		return
+	}
+	p.Downloads = append(p.Downloads, Download{Name: name, URL: url, Size: size, Destination: destination})
+}
+
+func (p *Plan) Directory(path string) {
+	if p == nil {
+		return
+	}
+	for _, dir := range p.Directories {
+		if dir == path {
+			return
+		}
+	}
+	p.Directories = append(p.Directories, path)
+}
+
+func (p *Plan) RegistryChange(key, value, from, to string) {
+	if p == nil {
+		return
+	}
+	p.Registry = append(p.Registry, RegistryChange{Key: key, Value: value, From: from, To: to})
+}
+
+func (p *Plan) Command(path string, args ...string) {
+	if p == nil {
+		return
+	}
+	p.Commands = append(p.Commands, Command{Path: path, Args: args})
+}
+
+// Note records a decision that was taken, such as skipping something
+func (p *Plan) Note(format string, a ...interface{}) {
+	if p == nil {
+		return
+	}
+	p.Notes = append(p.Notes, fmt.Sprintf(format, a...))
+}
+
+func (p *Plan) Print() {
+	heading := color.New(color.Bold)
+
+	heading.Println("Downloads:")
+	for _, download := range p.Downloads {
+		fmt.Printf("  %v (%v) from %v to '%v'\n", download.Name, FormatSize(download.Size), download.URL, download.Destination)
+	}
+	fmt.Printf("  Total: %v\n", p.totalSize())
+
+	heading.Println("Directories written to:")
+	for _, dir := range p.Directories {
+		fmt.Printf("  %v\n", dir)
+	}
+
+	heading.Println("Registry changes:")
+	for _, change := range p.Registry {
+		fmt.Printf("  %v\\%v: '%v' -> '%v'\n", change.Key, change.Value, change.From, change.To)
+	}
+
+	heading.Println("Commands run:")
+	for _, command := range p.Commands {
+		fmt.Printf("  %v %v\n", command.Path, strings.Join(command.Args, " "))
+	}
+
+	heading.Println("Notes:")
+	for _, note := range p.Notes {
+		fmt.Printf("  %v\n", note)
+	}
+}
+
+// totalSize formats the size of all the downloads. Unknown sizes are not counted, they are mentioned instead
+func (p *Plan) totalSize() string {
+	var total int64
+	unknown := false
+	for _, download := range p.Downloads {
+		if download.Size < 0 {
+			unknown = true
+			continue
+		}
+		total += download.Size
+	}
+	if !unknown {
+		return FormatSize(total)
+	}
+	if total == 0 {
+		return "unknown"
+	}
+	return FormatSize(total) + " + unknown"
+}
+
+// FormatSize formats a size in bytes for humans. Unknown (negative) sizes are shown as such
+func FormatSize(size int64) string {
+	if size < 0 {
+		return "unknown size"
+	}
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,29 @@
+package plan
+
+import (
+	"testing"
+)
+
+func TestTotalSize(t *testing.T) {
+	tests := []struct {
+		name  string
+		sizes []int64
+		want  string
+	}{
+		{name: "nothing", want: "0 B"},
+		{name: "known sizes", sizes: []int64{512, 1536}, want: "2.0 KiB"},
+		{name: "unknown size", sizes: []int64{-1}, want: "unknown"},
+		{name: "known and unknown sizes", sizes: []int64{2048, -1, -1}, want: "2.0 KiB + unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New()
+			for _, size := range tt.sizes {
+				p.Download("file", "https://example.com/file", size, "dir")
+			}
+			if got := p.totalSize(); got != tt.want {
+				t.Errorf("totalSize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package progress
+
+import (
+	"fmt"
+	"github.com/satisfactorymodding/SMEI/lib/plan"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const barWidth = 30
+
+// Bar shows the progress of a byte transfer with its rate and ETA
+type Bar struct {
+	task    *task
+	total   int64
+	done    int64
+	initial int64
+	started int32
+}
+
+// NewBar starts showing the progress of a transfer of total bytes. total is unknown if not positive
+func NewBar(name string, total int64) *Bar {
+	b := &Bar{total: total}
+	b.task = startTask(name, b.status)
+	return b
+}
+
+// Set sets how many bytes are done. The first value is not counted in the rate, as it is usually resumed from a previous run
+func (b *Bar) Set(done int64) {
+	if atomic.CompareAndSwapInt32(&b.started, 0, 1) {
+		atomic.StoreInt64(&b.initial, done)
+	}
+	atomic.StoreInt64(&b.done, done)
+}
+
+// Part returns a function setting the progress of one of several concurrent transfers counted by the bar.
+// As with Set, the first value is not counted in the rate. The function must not be called concurrently
+func (b *Bar) Part() func(done int64) {
+	atomic.StoreInt32(&b.started, 1)
+	first := true
+	var last int64
+	return func(done int64) {
+		if first {
+			first = false
+			atomic.AddInt64(&b.initial, done)
+		}
+		atomic.AddInt64(&b.done, done-last)
+		last = done
+	}
+}
+
+// Done stops the bar and prints how long the transfer took
+func (b *Bar) Done() {
+	done := atomic.LoadInt64(&b.done)
+	b.task.finish(fmt.Sprintf("%v in %v", plan.FormatSize(done), FormatDuration(time.Since(b.task.start))))
+}
+
+// Fail stops the bar without reporting the transfer as done
+func (b *Bar) Fail() {
+	b.task.finish(fmt.Sprintf("failed after %v", FormatDuration(time.Since(b.task.start))))
+}
+
+func (b *Bar) status(elapsed time.Duration) string {
+	done := atomic.LoadInt64(&b.done)
+	total := atomic.LoadInt64(&b.total)
+
+	rate := 0.0
+	if seconds := elapsed.Seconds(); seconds > 0 {
+		rate = float64(done-atomic.LoadInt64(&b.initial)) / seconds
+	}
+	speed := plan.FormatSize(int64(rate)) + "/s"
+
+	if total <= 0 {
+		return fmt.Sprintf("%v, %v", plan.FormatSize(done), speed)
+	}
+
+	ratio := float64(done) / float64(total)
+	if ratio > 1 {
+		ratio = 1
+	}
+	filled := int(ratio * barWidth)
+	eta := "unknown"
+	if rate > 0 {
+		eta = FormatDuration(time.Duration(float64(total-done) / rate * float64(time.Second)))
+	}
+	return fmt.Sprintf("[%v%v] %3.0f%% %v/%v, %v, ETA %v",
+		strings.Repeat("=", filled), strings.Repeat(" ", barWidth-filled), ratio*100,
+		plan.FormatSize(done), plan.FormatSize(total), speed, eta)
+}
@@ -0,0 +1,59 @@
+package progress
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Phases shows the latest message written to it, such as the progress of a git clone.
+// Messages are separated by carriage returns or new lines
+type Phases struct {
+	task    *task
+	mu      sync.Mutex
+	pending []byte
+	latest  string
+}
+
+func NewPhases(name string) *Phases {
+	p := &Phases{}
+	p.task = startTask(name, p.status)
+	return p
+}
+
+func (p *Phases) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.pending = append(p.pending, b...)
+	for {
+		i := bytes.IndexAny(p.pending, "\r\n")
+		if i < 0 {
+			break
+		}
+		if message := strings.TrimSpace(string(p.pending[:i])); message != "" {
+			p.latest = message
+		}
+		p.pending = p.pending[i+1:]
+	}
+	return len(b), nil
+}
+
+func (p *Phases) status(elapsed time.Duration) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.latest == "" {
+		return fmt.Sprintf("starting (%v)", FormatDuration(elapsed))
+	}
+	return fmt.Sprintf("%v (%v)", p.latest, FormatDuration(elapsed))
+}
+
+func (p *Phases) Done() {
+	p.task.finish(fmt.Sprintf("done in %v", FormatDuration(time.Since(p.task.start))))
+}
+
+func (p *Phases) Fail() {
+	p.task.finish(fmt.Sprintf("failed after %v", FormatDuration(time.Since(p.task.start))))
+}
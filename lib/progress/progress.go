@@ -0,0 +1,138 @@
+package progress
+
+import (
+	"fmt"
+	"github.com/satisfactorymodding/SMEI/lib/report"
+	"github.com/satisfactorymodding/SMEI/lib/runlog"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+)
+
+const (
+	redrawInterval = 200 * time.Millisecond
+	// How often a line is printed when progress cannot be redrawn in place
+	plainInterval = 15 * time.Second
+)
+
+var quiet bool
+
+// SetQuiet hides progress entirely, including the output of the tasks. It is still written to the run log
+func SetQuiet(q bool) {
+	quiet = q
+}
+
+// Interactive reports whether progress is redrawn in place. Otherwise, such as when stdout is redirected or in JSON mode,
+// plain lines are printed periodically
+func Interactive() bool {
+	return !report.IsJSON() && (isatty.IsTerminal(os.Stdout.Fd()) || isatty.IsCygwinTerminal(os.Stdout.Fd()))
+}
+
+// task redraws a status line until stopped. color.Output is used as it translates the escape sequences on older Windows consoles
+type task struct {
+	name        string
+	start       time.Time
+	interactive bool
+	quiet       bool
+	status      func(elapsed time.Duration) string
+	// mu guards the output, so that pass-through writes and redraws do not interleave
+	mu        sync.Mutex
+	lastPlain time.Time
+	drawn     bool
+	stop      chan struct{}
+	stopped   chan struct{}
+}
+
+func startTask(name string, status func(elapsed time.Duration) string) *task {
+	t := &task{
+		name:        name,
+		start:       time.Now(),
+		interactive: Interactive(),
+		quiet:       quiet,
+		status:      status,
+		stop:        make(chan struct{}),
+		stopped:     make(chan struct{}),
+	}
+	t.lastPlain = t.start
+	go t.loop()
+	return t
+}
+
+func (t *task) loop() {
+	defer close(t.stopped)
+	ticker := time.NewTicker(redrawInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-t.stop:
+			return
+		case now := <-ticker.C:
+			t.draw(now)
+		}
+	}
+}
+
+func (t *task) draw(now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.quiet {
+		return
+	}
+	if t.interactive {
+		fmt.Fprintf(color.Output, "\r\x1b[K%v: %v", t.name, t.status(now.Sub(t.start)))
+		t.drawn = true
+		return
+	}
+	if now.Sub(t.lastPlain) >= plainInterval {
+		fmt.Fprintf(color.Output, "%v: %v\n", t.name, t.status(now.Sub(t.start)))
+		t.lastPlain = now
+	}
+}
+
+// clear removes the status line so that something else can be printed. Must be called with mu held
+func (t *task) clear() {
+	if t.drawn {
+		fmt.Fprint(color.Output, "\r\x1b[K")
+		t.drawn = false
+	}
+}
+
+// finish stops redrawing and prints the final line
+func (t *task) finish(summary string) {
+	close(t.stop)
+	<-t.stopped
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.clear()
+	runlog.Printf("%v: %v", t.name, summary)
+	if !t.quiet {
+		fmt.Fprintf(color.Output, "%v: %v\n", t.name, summary)
+	}
+}
+
+// passThrough writes output of the task above its status line
+type passThrough struct {
+	t *task
+	w io.Writer
+}
+
+func (p passThrough) Write(b []byte) (int, error) {
+	p.t.mu.Lock()
+	defer p.t.mu.Unlock()
+	if p.t.quiet {
+		return len(b), nil
+	}
+	p.t.clear()
+	return p.w.Write(b)
+}
+
+// FormatDuration formats d rounded to the second, such as 1m05s
+func FormatDuration(d time.Duration) string {
+	return d.Round(time.Second).String()
+}
@@ -0,0 +1,51 @@
+package progress
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+var spinnerFrames = []string{"|", "/", "-", "\\"}
+
+// Spinner shows that a task of unknown length is still running and for how long
+type Spinner struct {
+	task *task
+}
+
+func NewSpinner(name string) *Spinner {
+	return &Spinner{task: startTask(name, spinnerStatus)}
+}
+
+func spinnerStatus(elapsed time.Duration) string {
+	frame := spinnerFrames[int(elapsed/redrawInterval)%len(spinnerFrames)]
+	return fmt.Sprintf("%v running for %v", frame, FormatDuration(elapsed))
+}
+
+// Writer returns a writer printing above the spinner, for the output of the task
+func (s *Spinner) Writer() io.Writer {
+	return passThrough{t: s.task, w: color.Output}
+}
+
+// Done stops the spinner and prints how long the task took
+func (s *Spinner) Done() {
+	s.task.finish(fmt.Sprintf("done in %v", FormatDuration(time.Since(s.task.start))))
+}
+
+func (s *Spinner) Fail() {
+	s.task.finish(fmt.Sprintf("failed after %v", FormatDuration(time.Since(s.task.start))))
+}
+
+// Run runs fn while showing a spinner. fn can print the output of the task above the spinner with w
+func Run(name string, fn func(w io.Writer) error) error {
+	s := NewSpinner(name)
+	err := fn(s.Writer())
+	if err != nil {
+		s.Fail()
+		return err
+	}
+	s.Done()
+	return nil
+}
@@ -0,0 +1,96 @@
+package registry
+
+import (
+	"sync"
+)
+
+// Fake is an in-memory registry
+type Fake struct {
+	lock sync.Mutex
+	keys map[Root]map[string]map[string]string
+}
+
+func NewFake() *Fake {
+	return &Fake{keys: map[Root]map[string]map[string]string{}}
+}
+
+// Set creates the key if needed and sets one of its values
+func (f *Fake) Set(root Root, path, name, value string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.create(root, path)[name] = value
+}
+
+// Value returns a value of a key, and whether it exists
+func (f *Fake) Value(root Root, path, name string) (string, bool) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	value, ok := f.keys[root][path][name]
+	return value, ok
+}
+
+// Delete removes a key and its values
+func (f *Fake) Delete(root Root, path string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	delete(f.keys[root], path)
+}
+
+func (f *Fake) OpenKey(root Root, path string, write bool) (Key, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if _, ok := f.keys[root][path]; !ok {
+		return nil, ErrNotExist
+	}
+	return &fakeKey{registry: f, root: root, path: path, write: write}, nil
+}
+
+func (f *Fake) CreateKey(root Root, path string) (Key, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.create(root, path)
+	return &fakeKey{registry: f, root: root, path: path, write: true}, nil
+}
+
+func (f *Fake) create(root Root, path string) map[string]string {
+	if f.keys[root] == nil {
+		f.keys[root] = map[string]map[string]string{}
+	}
+	if f.keys[root][path] == nil {
+		f.keys[root][path] = map[string]string{}
+	}
+	return f.keys[root][path]
+}
+
+type fakeKey struct {
+	registry *Fake
+	root     Root
+	path     string
+	write    bool
+}
+
+func (k *fakeKey) GetStringValue(name string) (string, error) {
+	value, ok := k.registry.Value(k.root, k.path, name)
+	if !ok {
+		return "", ErrNotExist
+	}
+	return value, nil
+}
+
+func (k *fakeKey) SetStringValue(name, value string) error {
+	if !k.write {
+		return errAccessDenied
+	}
+	k.registry.lock.Lock()
+	defer k.registry.lock.Unlock()
+	values, ok := k.registry.keys[k.root][k.path]
+	if !ok {
+		return ErrNotExist
+	}
+	values[name] = value
+	return nil
+}
+
+func (k *fakeKey) Close() error {
+	return nil
+}
@@ -0,0 +1,35 @@
+package registry
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Root is a predefined registry key
+type Root string
+
+const (
+	CurrentUser  Root = "HKCU"
+	LocalMachine Root = "HKLM"
+)
+
+// Roots in the order Windows installers are looked up in
+var Roots = []Root{CurrentUser, LocalMachine}
+
+// ErrNotExist is returned when a key or a value does not exist
+var ErrNotExist = errors.New("the registry key or value does not exist")
+
+// Registry is the part of the Windows registry SMEI reads and changes
+type Registry interface {
+	// OpenKey opens an existing key, for reading only unless write is set
+	OpenKey(root Root, path string, write bool) (Key, error)
+	// CreateKey opens a key for writing, creating it if needed
+	CreateKey(root Root, path string) (Key, error)
+}
+
+type Key interface {
+	GetStringValue(name string) (string, error)
+	SetStringValue(name, value string) error
+	Close() error
+}
+
+var errAccessDenied = errors.New("the key was not opened for writing")
@@ -0,0 +1,9 @@
+//go:build !windows
+// +build !windows
+
+package registry
+
+// System returns an empty registry, as only Windows has one
+func System() Registry {
+	return NewFake()
+}
@@ -0,0 +1,70 @@
+package registry
+
+import (
+	"github.com/pkg/errors"
+	"golang.org/x/sys/windows/registry"
+)
+
+var roots = map[Root]registry.Key{
+	CurrentUser:  registry.CURRENT_USER,
+	LocalMachine: registry.LOCAL_MACHINE,
+}
+
+// System returns the registry of the machine
+func System() Registry {
+	return windowsRegistry{}
+}
+
+type windowsRegistry struct{}
+
+func (windowsRegistry) OpenKey(root Root, path string, write bool) (Key, error) {
+	rootKey, ok := roots[root]
+	if !ok {
+		return nil, errors.Errorf("unknown registry root '%v'", root)
+	}
+	access := uint32(registry.QUERY_VALUE)
+	if write {
+		access |= registry.SET_VALUE
+	}
+	key, err := registry.OpenKey(rootKey, path, access)
+	if err != nil {
+		return nil, translate(err)
+	}
+	return windowsKey{key}, nil
+}
+
+func (windowsRegistry) CreateKey(root Root, path string) (Key, error) {
+	rootKey, ok := roots[root]
+	if !ok {
+		return nil, errors.Errorf("unknown registry root '%v'", root)
+	}
+	key, _, err := registry.CreateKey(rootKey, path, registry.QUERY_VALUE|registry.SET_VALUE)
+	if err != nil {
+		return nil, err
+	}
+	return windowsKey{key}, nil
+}
+
+type windowsKey struct {
+	key registry.Key
+}
+
+func (k windowsKey) GetStringValue(name string) (string, error) {
+	value, _, err := k.key.GetStringValue(name)
+	return value, translate(err)
+}
+
+func (k windowsKey) SetStringValue(name, value string) error {
+	return k.key.SetStringValue(name, value)
+}
+
+func (k windowsKey) Close() error {
+	return k.key.Close()
+}
+
+func translate(err error) error {
+	if errors.Is(err, registry.ErrNotExist) {
+		return ErrNotExist
+	}
+	return err
+}
@@ -0,0 +1,35 @@
+package report
+
+import (
+	"errors"
+)
+
+// HintedError carries a remediation hint for the user alongside an error
+type HintedError struct {
+	Err  error
+	Hint string
+}
+
+func (e HintedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e HintedError) Unwrap() error {
+	return e.Err
+}
+
+func WithHint(err error, hint string) error {
+	if err == nil {
+		return nil
+	}
+	return HintedError{Err: err, Hint: hint}
+}
+
+// HintOf returns the first hint found in the error chain, or an empty string
+func HintOf(err error) string {
+	var hinted HintedError
+	if errors.As(err, &hinted) {
+		return hinted.Hint
+	}
+	return ""
+}
@@ -0,0 +1,169 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/satisfactorymodding/SMEI/lib/cfmt"
+	"github.com/satisfactorymodding/SMEI/lib/runlog"
+	"io"
+	"os"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/pkg/errors"
+)
+
+type Format string
+
+const (
+	Text Format = "text"
+	JSON Format = "json"
+)
+
+var Formats = []Format{Text, JSON}
+
+var format = Text
+
+// Where events are written. Human-readable output is moved to stderr in JSON mode so this stays parseable
+var out io.Writer = os.Stdout
+
+func SetFormat(f string) error {
+	switch Format(f) {
+	case Text:
+		format = Text
+	case JSON:
+		format = JSON
+		out = os.Stdout
+		os.Stdout = os.Stderr
+		color.Output = color.Error
+	default:
+		return errors.Errorf("unknown output format '%v', expected one of %v", f, Formats)
+	}
+	return nil
+}
+
+func IsJSON() bool {
+	return format == JSON
+}
+
+type Status string
+
+const (
+	OK      Status = "ok"
+	Failed  Status = "failed"
+	Skipped Status = "skipped"
+	Warning Status = "warning"
+)
+
+type Event struct {
+	Type       string `json:"type"`
+	Step       string `json:"step"`
+	Status     Status `json:"status"`
+	DurationMs int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+	Hint       string `json:"hint,omitempty"`
+}
+
+type Summary struct {
+	Type       string      `json:"type"`
+	Status     Status      `json:"status"`
+	DurationMs int64       `json:"duration_ms"`
+	Steps      int         `json:"steps"`
+	Failed     []string    `json:"failed"`
+	Data       interface{} `json:"data,omitempty"`
+}
+
+// Run collects the events of a single command run
+type Run struct {
+	start  time.Time
+	events []Event
+}
+
+func Start() *Run {
+	return &Run{start: time.Now()}
+}
+
+// Step runs fn and reports its outcome. The error from fn is returned as-is
+func (r *Run) Step(name string, fn func() error) error {
+	start := time.Now()
+	err := fn()
+	status := OK
+	if err != nil {
+		status = Failed
+	}
+	r.emit(name, status, time.Since(start), err, HintOf(err))
+	return err
+}
+
+// Record reports the outcome of a check that was not timed
+func (r *Run) Record(name string, status Status, err error, hint string) {
+	r.emit(name, status, 0, err, hint)
+}
+
+func (r *Run) Skip(name string) {
+	r.emit(name, Skipped, 0, nil, "")
+}
+
+func (r *Run) emit(name string, status Status, duration time.Duration, err error, hint string) {
+	event := Event{
+		Type:       "step",
+		Step:       name,
+		Status:     status,
+		DurationMs: duration.Milliseconds(),
+		Hint:       hint,
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	r.events = append(r.events, event)
+	runlog.Printf("Step '%v' %v in %v", name, status, duration.Round(time.Millisecond))
+	if err != nil {
+		runlog.Printf("Step '%v' error: %v", name, err)
+	}
+
+	if IsJSON() {
+		write(event)
+	} else if hint != "" && status != OK {
+		cfmt.Warning.Println(hint)
+	}
+}
+
+// Failed reports whether any step failed so far
+func (r *Run) Failed() bool {
+	for _, event := range r.events {
+		if event.Status == Failed {
+			return true
+		}
+	}
+	return false
+}
+
+// Finish writes the summary of the run. data is included as-is in JSON mode
+func (r *Run) Finish(data interface{}) {
+	if !IsJSON() {
+		return
+	}
+
+	summary := Summary{
+		Type:       "summary",
+		Status:     OK,
+		DurationMs: time.Since(r.start).Milliseconds(),
+		Steps:      len(r.events),
+		Failed:     []string{},
+		Data:       data,
+	}
+	for _, event := range r.events {
+		if event.Status == Failed {
+			summary.Status = Failed
+			summary.Failed = append(summary.Failed, event.Step)
+		}
+	}
+	write(summary)
+}
+
+func write(v interface{}) {
+	err := json.NewEncoder(out).Encode(v)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not write a JSON event: %v\n", err)
+	}
+}
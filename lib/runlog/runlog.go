@@ -0,0 +1,293 @@
+package runlog
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/satisfactorymodding/SMEI/config"
+	"github.com/satisfactorymodding/SMEI/lib/cfmt"
+	"github.com/satisfactorymodding/SMEI/lib/command"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/pkg/errors"
+)
+
+const folder = "logs"
+const extension = ".log"
+
+// How many logs are kept, including the one of the current run. The oldest ones are removed when a run starts
+const kept = 50
+
+// The first line of every log, followed by the command line
+const header = "# smei"
+
+type Level int
+
+const (
+	Quiet Level = iota - 1
+	Normal
+	Verbose
+)
+
+var lock sync.Mutex
+var file *os.File
+var current string
+var level = Normal
+
+func Dir() string {
+	return filepath.Join(config.CacheDir, folder)
+}
+
+func SetLevel(l Level) {
+	lock.Lock()
+	defer lock.Unlock()
+	level = l
+}
+
+func IsVerbose() bool {
+	lock.Lock()
+	defer lock.Unlock()
+	return level >= Verbose
+}
+
+// Start creates the log of this run, args being the arguments of the command line. Messages of the log package and every command run through lib/command are written to it from then on.
+// The values of secretFlags are not written
+func Start(args []string, secretFlags []string) error {
+	err := os.MkdirAll(Dir(), 0755)
+	if err != nil {
+		return errors.Wrap(err, "could not create the log directory")
+	}
+	err = prune()
+	if err != nil {
+		return errors.Wrap(err, "could not remove the old logs")
+	}
+
+	f, path, err := create(time.Now())
+	if err != nil {
+		return err
+	}
+
+	lock.Lock()
+	file = f
+	current = path
+	lock.Unlock()
+
+	fmt.Fprintf(Writer(), "%v %v\n", header, strings.Join(redact(args, secretFlags), " "))
+	Printf("Started in '%v'", workingDir())
+
+	log.SetOutput(io.MultiWriter(os.Stderr, Writer()))
+	cfmt.SetLog(Writer())
+	runner := &command.ExecRunner{Log: Writer()}
+	if IsVerbose() {
+		runner.Trace = color.Error
+	}
+	command.Use(runner)
+	return nil
+}
+
+// create opens a new log named after start, suffixed if a log of the same second exists
+func create(start time.Time) (*os.File, string, error) {
+	name := start.Format("2006-01-02_15-04-05")
+	for i := 1; ; i++ {
+		path := filepath.Join(Dir(), name+extension)
+		if i > 1 {
+			path = filepath.Join(Dir(), fmt.Sprintf("%v_%v%v", name, i, extension))
+		}
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if os.IsExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, "", errors.Wrap(err, "could not create the log")
+		}
+		return f, path, nil
+	}
+}
+
+func workingDir() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "unknown"
+	}
+	return dir
+}
+
+// redact replaces the values of secretFlags, given as --flag=value or --flag value
+func redact(args []string, secretFlags []string) []string {
+	r := make([]string, len(args))
+	copy(r, args)
+	for i := 0; i < len(r); i++ {
+		for _, flag := range secretFlags {
+			if r[i] == "--"+flag && i+1 < len(r) {
+				r[i+1] = "<secret>"
+				i++
+				break
+			}
+			if strings.HasPrefix(r[i], "--"+flag+"=") {
+				r[i] = "--" + flag + "=<secret>"
+				break
+			}
+		}
+	}
+	return r
+}
+
+// Current is the path of the log of this run. Empty if there is none
+func Current() string {
+	lock.Lock()
+	defer lock.Unlock()
+	return current
+}
+
+func Close() error {
+	lock.Lock()
+	defer lock.Unlock()
+	if file == nil {
+		return nil
+	}
+	err := file.Close()
+	file = nil
+	return err
+}
+
+type writer struct{}
+
+func (writer) Write(b []byte) (int, error) {
+	lock.Lock()
+	defer lock.Unlock()
+	if file == nil {
+		return len(b), nil
+	}
+	return file.Write(b)
+}
+
+// Writer writes to the log as-is. Nothing is written before Start
+func Writer() io.Writer {
+	return writer{}
+}
+
+// Printf writes a timestamped line to the log
+func Printf(format string, args ...interface{}) {
+	fmt.Fprintf(Writer(), "%v %v\n", time.Now().Format("15:04:05"), fmt.Sprintf(format, args...))
+}
+
+// Debugf writes a timestamped line to the log, and to the console with --verbose
+func Debugf(format string, args ...interface{}) {
+	Printf(format, args...)
+	if IsVerbose() {
+		fmt.Fprintf(color.Error, format+"\n", args...)
+	}
+}
+
+type Info struct {
+	Name     string
+	Path     string
+	Size     int64
+	Modified time.Time
+	// Command is the command line of the run
+	Command string
+}
+
+// List returns the logs, newest first
+func List() ([]Info, error) {
+	files, err := os.ReadDir(Dir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list the logs")
+	}
+
+	var r []Info
+	for _, f := range files {
+		if f.IsDir() || filepath.Ext(f.Name()) != extension {
+			continue
+		}
+		stat, err := f.Info()
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not get the info of '%v'", f.Name())
+		}
+		path := filepath.Join(Dir(), f.Name())
+		r = append(r, Info{
+			Name:     strings.TrimSuffix(f.Name(), extension),
+			Path:     path,
+			Size:     stat.Size(),
+			Modified: stat.ModTime(),
+			Command:  readCommand(path),
+		})
+	}
+	// Names are timestamps, so they sort chronologically
+	sort.Slice(r, func(i, j int) bool {
+		return r[i].Name > r[j].Name
+	})
+	return r, nil
+}
+
+func readCommand(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	line, _ := bufio.NewReader(f).ReadString('\n')
+	if !strings.HasPrefix(line, header) {
+		return ""
+	}
+	return "smei" + strings.TrimSuffix(strings.TrimPrefix(line, header), "\n")
+}
+
+// Find returns the log named name, with or without its extension
+func Find(name string) (Info, error) {
+	logs, err := List()
+	if err != nil {
+		return Info{}, err
+	}
+	name = strings.TrimSuffix(name, extension)
+	for _, info := range logs {
+		if info.Name == name {
+			return info, nil
+		}
+	}
+	return Info{}, errors.Errorf("there is no log named '%v'. See 'smei logs list'", name)
+}
+
+// Last returns the newest log of a previous run
+func Last() (Info, error) {
+	logs, err := List()
+	if err != nil {
+		return Info{}, err
+	}
+	for _, info := range logs {
+		if info.Path != Current() {
+			return info, nil
+		}
+	}
+	return Info{}, errors.New("there is no log yet")
+}
+
+// prune removes the oldest logs so that kept remain once the log of this run is created
+func prune() error {
+	logs, err := List()
+	if err != nil {
+		return err
+	}
+	// Leave room for the log of this run
+	keep := kept - 1
+	if len(logs) <= keep {
+		return nil
+	}
+	for _, info := range logs[keep:] {
+		err = os.Remove(info.Path)
+		if err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "could not remove '%v'", info.Path)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package runlog
+
+import (
+	"github.com/satisfactorymodding/SMEI/config"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRedact(t *testing.T) {
+	secretFlags := []string{"wwise-password", "token"}
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{
+			name: "flag then value",
+			args: []string{"install", "--wwise-password", "hunter2", "--target", "SML"},
+			want: []string{"install", "--wwise-password", "<secret>", "--target", "SML"},
+		},
+		{
+			name: "flag with equals",
+			args: []string{"install", "--token=ghp_secret", "--target=SML"},
+			want: []string{"install", "--token=<secret>", "--target=SML"},
+		},
+		{
+			name: "trailing flag without value",
+			args: []string{"install", "--target", "SML", "--wwise-password"},
+			want: []string{"install", "--target", "SML", "--wwise-password"},
+		},
+		{
+			name: "similar flag names are kept",
+			args: []string{"install", "--token-file", "token.txt", "--tokens=3"},
+			want: []string{"install", "--token-file", "token.txt", "--tokens=3"},
+		},
+		{
+			name: "every secret is redacted",
+			args: []string{"--token", "a", "--wwise-password=b", "--token=c"},
+			want: []string{"--token", "<secret>", "--wwise-password=<secret>", "--token=<secret>"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{}, tt.args...)
+			got := redact(args, secretFlags)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("redact() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("redact() modified its arguments to %v", args)
+			}
+		})
+	}
+}
+
+func TestPrune(t *testing.T) {
+	tests := []struct {
+		name string
+		logs int
+		want int
+	}{
+		{name: "no logs", logs: 0, want: 0},
+		{name: "below the limit", logs: kept - 2, want: kept - 2},
+		{name: "room for one more", logs: kept - 1, want: kept - 1},
+		{name: "at the limit", logs: kept, want: kept - 1},
+		{name: "over the limit", logs: kept + 10, want: kept - 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cacheDir := config.CacheDir
+			config.CacheDir = t.TempDir()
+			t.Cleanup(func() {
+				config.CacheDir = cacheDir
+			})
+			err := os.MkdirAll(Dir(), 0755)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+			for i := 0; i < tt.logs; i++ {
+				name := start.Add(time.Duration(i)*time.Minute).Format("2006-01-02_15-04-05") + extension
+				err = os.WriteFile(filepath.Join(Dir(), name), []byte(header+"\n"), 0644)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err = prune()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			logs, err := List()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(logs) != tt.want {
+				t.Fatalf("%v logs left, want %v", len(logs), tt.want)
+			}
+			if len(logs) > 0 {
+				newest := start.Add(time.Duration(tt.logs-1) * time.Minute).Format("2006-01-02_15-04-05")
+				if logs[0].Name != newest {
+					t.Errorf("newest log left is %v, want %v", logs[0].Name, newest)
+				}
+			}
+
+			// The log of the next run brings the total to at most kept
+			f, _, err := create(time.Now())
+			if err != nil {
+				t.Fatal(err)
+			}
+			f.Close()
+			logs, _ = List()
+			if len(logs) > kept {
+				t.Errorf("%v logs after the next run, want at most %v", len(logs), kept)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package state
+
+import (
+	"encoding/json"
+	"github.com/satisfactorymodding/SMEI/config"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const filename = "state.json"
+
+// State records what SMEI installed, so that only those installs are ever removed
+type State struct {
+	UE       *UEInstall `json:"ue,omitempty"`
+	VS       *Install   `json:"vs,omitempty"`
+	Projects []Install  `json:"projects,omitempty"`
+	// ReplacedUninstaller is the uninstall entry of an unrelated engine install, saved before SMEI disables it
+	ReplacedUninstaller *UninstallEntry `json:"replacedUninstaller,omitempty"`
+}
+
+type Install struct {
+	Location  string    `json:"location"`
+	Installed time.Time `json:"installed"`
+}
+
+type UEInstall struct {
+	Install
+	Tag string `json:"tag,omitempty"`
+}
+
+// UninstallEntry is the part of a Windows uninstall registry entry SMEI changes
+type UninstallEntry struct {
+	Root            string `json:"root"`
+	DisplayName     string `json:"displayName"`
+	InstallLocation string `json:"installLocation"`
+	UninstallString string `json:"uninstallString"`
+}
+
+var lock sync.Mutex
+
+// Path is where the state is stored
+func Path() string {
+	return filepath.Join(config.ConfigDir, filename)
+}
+
+// Load reads the state. A missing state is not an error, an empty one is returned instead
+func Load() (*State, error) {
+	s := &State{}
+	data, err := os.ReadFile(Path())
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read the state")
+	}
+	err = json.Unmarshal(data, s)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse the state")
+	}
+	return s, nil
+}
+
+func (s *State) save() error {
+	err := os.MkdirAll(config.ConfigDir, 0744)
+	if err != nil {
+		return errors.Wrap(err, "could not create the config directory")
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "could not marshal the state")
+	}
+
+	tmp := Path() + ".tmp"
+	err = os.WriteFile(tmp, data, 0644)
+	if err != nil {
+		return errors.Wrap(err, "could not write the state")
+	}
+	err = os.Rename(tmp, Path())
+	if err != nil {
+		return errors.Wrap(err, "could not replace the state")
+	}
+	return nil
+}
+
+// Update loads the state, applies fn and saves the result
+func Update(fn func(s *State)) error {
+	lock.Lock()
+	defer lock.Unlock()
+
+	s, err := Load()
+	if err != nil {
+		return err
+	}
+	fn(s)
+	return s.save()
+}
+
+// FindProject returns the recorded project at location, nil if SMEI did not clone it
+func (s *State) FindProject(location string) *Install {
+	for i := range s.Projects {
+		if SamePath(s.Projects[i].Location, location) {
+			return &s.Projects[i]
+		}
+	}
+	return nil
+}
+
+// RemoveProject forgets the project at location
+func (s *State) RemoveProject(location string) {
+	var kept []Install
+	for _, project := range s.Projects {
+		if !SamePath(project.Location, location) {
+			kept = append(kept, project)
+		}
+	}
+	s.Projects = kept
+}
+
+// SamePath compares two paths the way Windows does, ignoring case and trailing separators
+func SamePath(a, b string) bool {
+	a, errA := filepath.Abs(a)
+	b, errB := filepath.Abs(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	return strings.EqualFold(filepath.Clean(a), filepath.Clean(b))
+}